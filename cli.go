@@ -0,0 +1,693 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"terrain-generation/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//==========================================Commands==========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// A subcommand of the program
+type command struct {
+	name string
+	// The arguments of the command after its flags
+	args string
+	// A one line description of the command
+	summary string
+	// Adds the flags of the command to its flag set, and returns the function that runs it with the arguments left after the flags
+	setup func(flags *flag.FlagSet) func(args []string) error
+}
+
+// The subcommands of the program, set up by init since the help command lists them
+var commands []command
+
+func init() {
+	commands = []command{
+		{"view", "[flags] <map>", "Opens a map in the viewer", setupView},
+		{"export", "[flags] <map> [output files...]", "Writes the terrain of a map to output files, their format is chosen by their extension or -format", setupExport},
+		{"info", "[flags] <map>...", "Prints where maps are read from and what they generate", setupInfo},
+		{"validate", "[flags] <map>...", "Checks maps for problems without generating them", setupValidate},
+		{"convert", "[flags] <map> [output file]", "Rewrites a map as json, yaml or toml, chosen by the extension of the output file or -format", setupConvert},
+		{"resolve", "[flags] <map> [output file]", "Writes a map merged with the maps it extends, as convert does", setupResolve},
+		{"stats", "[flags] <map>", "Generates the terrain of a map and prints statistics of its heights", setupStats},
+		{"batch", "[flags] <jobs file>", "Runs an export for every line of a file of export arguments, - reads the jobs from stdin", setupBatch},
+		{"help", "[command]", "Prints the usage of the program or of a command", setupHelp},
+	}
+}
+
+// An error in the arguments of a command, which exits with status 2 after printing the usage of the command
+type usageError string
+
+func (err usageError) Error() string {
+	return string(err)
+}
+
+/*
+ * Finds a subcommand by name
+ * @param name The name of the command
+ */
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+/*
+ * Creates the flag set of a command, with the usage of the command
+ * @param cmd The command
+ */
+func newCommandFlags(cmd command) (*flag.FlagSet, func(args []string) error) {
+	flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: terrain-generation %s %s\n\n%s\n", cmd.name, cmd.args, cmd.summary)
+		if hasFlags(flags) {
+			fmt.Fprintln(flags.Output(), "\nFlags:")
+			flags.PrintDefaults()
+		}
+	}
+	return flags, cmd.setup(flags)
+}
+
+/*
+ * Parses the flags of a command and runs it
+ * @param cmd The command to run
+ * @param args The arguments after the name of the command
+ */
+func runCommand(cmd command, args []string) error {
+	flags, run := newCommandFlags(cmd)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		// The flag set has already printed the error and the usage of the command
+		return usageError("")
+	}
+	err := run(flags.Args())
+	var usage usageError
+	if errors.As(err, &usage) {
+		flags.Usage()
+	}
+	return err
+}
+
+// Whether any flags were added to a flag set
+func hasFlags(flags *flag.FlagSet) bool {
+	found := false
+	flags.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+// Prints the usage of the program and its commands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: terrain-generation <command> [flags] [arguments]")
+	fmt.Fprintln(w, "       terrain-generation [flags] <map> [<terrain_width> <terrain_height> [output files...]]")
+	fmt.Fprintln(w, "\nCommands:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "\nRun terrain-generation help <command> for the flags of a command.")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//==========================================mapFlags==========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The command line flags that choose the size of a terrain and override the seeds of its map
+type mapFlags struct {
+	flags    *flag.FlagSet
+	width    uint
+	height   uint
+	seed     int
+	seed1    int
+	seed2    int
+	warpSeed int
+}
+
+/*
+ * Adds the flags of the map and terrain size to the flags of a command
+ * @param flags The flags of the command
+ */
+func addMapFlags(flags *flag.FlagSet) *mapFlags {
+	mf := &mapFlags{flags: flags}
+	addMapPathFlag(flags)
+	flags.UintVar(&mf.width, "width", 124, "The number of vertices sampled in the x direction of the terrain, at least 2")
+	flags.UintVar(&mf.height, "height", 124, "The number of vertices sampled in the y direction of the terrain, at least 2")
+	flags.IntVar(&mf.seed1, "seed1", 0, "Replaces the seed1 of the map")
+	flags.IntVar(&mf.seed2, "seed2", 0, "Replaces the seed2 of the map")
+	flags.IntVar(&mf.warpSeed, "warpseed", 0, "Replaces the warp_seed of the map")
+	flags.IntVar(&mf.seed, "seed", 0, "Added to every seed of the map, including the seeds of its layers, to generate a variation of it")
+	return mf
+}
+
+/*
+ * Reads a map and overrides its seeds with the flags that were passed
+ * @param name The name or path of the map
+ */
+func (mf *mapFlags) load(name string) (TerrainMap, error) {
+	terrainMap, err := readTerrainMap(name)
+	if err != nil {
+		return terrainMap, err
+	}
+	mf.flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "seed1":
+			terrainMap.seed1 = int32(mf.seed1)
+		case "seed2":
+			terrainMap.seed2 = int32(mf.seed2)
+		case "warpseed":
+			terrainMap.warp_seed = int32(mf.warpSeed)
+		}
+	})
+	if mf.seed != 0 {
+		terrainMap.seed1 += int32(mf.seed)
+		terrainMap.seed2 += int32(mf.seed)
+		terrainMap.warp_seed += int32(mf.seed)
+		for i := range terrainMap.layers {
+			terrainMap.layers[i].seed += int32(mf.seed)
+		}
+	}
+	return terrainMap, nil
+}
+
+/*
+ * Reads a map and generates its terrain at the size of the flags
+ * @param name The name or path of the map
+ */
+func (mf *mapFlags) build(name string) (terrain.Terrain, TerrainMap, error) {
+	if mf.width < 2 || mf.height < 2 || mf.width > math.MaxUint32 || mf.height > math.MaxUint32 {
+		return nil, TerrainMap{}, usageError(fmt.Sprintf("the terrain needs a width and height of at least 2 vertices, not %d x %d", mf.width, mf.height))
+	}
+	terrainMap, err := mf.load(name)
+	if err != nil {
+		return nil, terrainMap, err
+	}
+	surface, err := buildTerrain(terrainMap, uint32(mf.width), uint32(mf.height))
+	return surface, terrainMap, err
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//========================================view/export=========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+func setupView(flags *flag.FlagSet) func(args []string) error {
+	mf := addMapFlags(flags)
+	return func(args []string) error {
+		if len(args) != 1 {
+			return usageError("view needs exactly one map")
+		}
+		surface, _, err := mf.build(args[0])
+		if err != nil {
+			return err
+		}
+		return viewTerrain(surface)
+	}
+}
+
+func setupExport(flags *flag.FlagSet) func(args []string) error {
+	mf := addMapFlags(flags)
+	of := addOutputFlags(flags)
+	output := flags.String("o", "", "An output file, added to the output files after the map. Without any, the terrain is written to <map name>.<format>")
+	return func(args []string) error {
+		if len(args) == 0 {
+			return usageError("export needs a map")
+		}
+		outputs := args[1:]
+		if *output != "" {
+			outputs = append(outputs, *output)
+		}
+		if len(outputs) == 0 {
+			if of.format == "" {
+				return usageError("export needs output files, -o or -format")
+			}
+			name := strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+			outputs = []string{name + "." + strings.TrimPrefix(strings.ToLower(of.format), ".")}
+		}
+		surface, terrainMap, err := mf.build(args[0])
+		if err != nil {
+			return err
+		}
+		return writeTerrain(surface, terrainMap, outputs, of)
+	}
+}
+
+/*
+ * Moves a terrain by the displacement of the output flags and writes it to the output files
+ * @param surface The generated terrain
+ * @param terrainMap The map of the terrain
+ * @param outputs The paths of the output files
+ * @param of The flags of the output files
+ */
+func writeTerrain(surface terrain.Terrain, terrainMap TerrainMap, outputs []string, of *outputFlags) error {
+	options, err := of.options(terrainMap)
+	if err != nil {
+		return err
+	}
+	surface.MoveRight(of.xDisp)
+	surface.MoveUp(of.yDisp)
+	return writeOutputs(surface.HeightField(), outputs, options)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=======================================info/validate========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The names of the typs of maps
+var typNames = map[uint8]string{1: "simple", 2: "bipartite", 3: "fractal", 4: "layered"}
+
+func setupInfo(flags *flag.FlagSet) func(args []string) error {
+	addMapPathFlag(flags)
+	return func(args []string) error {
+		if len(args) == 0 {
+			return usageError("info needs at least one map")
+		}
+		for i, name := range args {
+			if i > 0 {
+				fmt.Println()
+			}
+			terrainMap, err := readTerrainMap(name)
+			if err != nil {
+				return err
+			}
+			printTerrainMap(os.Stdout, terrainMap)
+		}
+		return nil
+	}
+}
+
+/*
+ * Prints a summary of a map and the boards it generates its terrain from
+ * @param w The writer the summary is printed to
+ * @param terrainMap The map to summarize
+ */
+func printTerrainMap(w io.Writer, terrainMap TerrainMap) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	source := terrainMap.source.path
+	if terrainMap.source.preset {
+		source += " (built-in preset)"
+	}
+	fmt.Fprintf(tw, "map\t%s\n", source)
+	fmt.Fprintf(tw, "typ\t%d (%s)\n", terrainMap.typ, typNames[terrainMap.typ])
+	fmt.Fprintf(tw, "magnitude\t%g\n", terrainMap.m)
+	board := func(name string, width, height uint32, seed int32, noise string) {
+		fmt.Fprintf(tw, "%s\t%d x %d gradients, seed %d, %s noise\n", name, width, height, seed, noiseName(noise))
+	}
+	switch terrainMap.typ {
+	case 1:
+		board("board", terrainMap.gradient_width_b1, terrainMap.gradient_height_b1, terrainMap.seed1, terrainMap.noise_b1)
+	case 2:
+		board("macro board", terrainMap.gradient_width_b1, terrainMap.gradient_height_b1, terrainMap.seed1, terrainMap.noise_b1)
+		board("micro board", terrainMap.gradient_width_b2, terrainMap.gradient_height_b2, terrainMap.seed2, terrainMap.noise_b2)
+		fmt.Fprintf(tw, "prop\t%g\n", terrainMap.prop)
+	case 3:
+		board("board", terrainMap.gradient_width_b1, terrainMap.gradient_height_b1, terrainMap.seed1, terrainMap.noise_b1)
+		fmt.Fprintf(tw, "fractal\t%s, %d octaves, lacunarity %g, persistence %g\n", terrainMap.fractal, terrainMap.octaves, terrainMap.lacunarity, terrainMap.persistence)
+	case 4:
+		for i, layer := range terrainMap.layers {
+			name := fmt.Sprintf("layer %d", i)
+			if layer.heightmap != "" {
+				fmt.Fprintf(tw, "%s\theightmap %s over %d x %d gradients", name, layer.heightmap, layer.gradient_width, layer.gradient_height)
+			} else {
+				fmt.Fprintf(tw, "%s\t%d x %d gradients, seed %d, %s noise, %s with %d octaves", name, layer.gradient_width, layer.gradient_height, layer.seed, noiseName(layer.noise), layer.fractal, layer.octaves)
+			}
+			fmt.Fprintf(tw, ", weight %g, offset %g, %s blend", layer.weight, layer.offset, layer.blend)
+			if layer.blend == "lerp" {
+				fmt.Fprintf(tw, " by layer %d", layer.mask)
+			}
+			fmt.Fprintln(tw)
+		}
+	}
+	if terrainMap.warp_strength != 0 {
+		fmt.Fprintf(tw, "warp\tstrength %g, seed %d, frequency %g, %d iterations, %s noise\n", terrainMap.warp_strength, terrainMap.warp_seed, terrainMap.warp_frequency, terrainMap.warp_iterations, noiseName(terrainMap.warp_noise))
+	}
+	fmt.Fprintf(tw, "periodic\t%v\n", terrainMap.periodic)
+	tw.Flush()
+}
+
+// The name of a noise backend of a map, where perlin is the default
+func noiseName(noise string) string {
+	if noise == "" {
+		return "perlin"
+	}
+	return noise
+}
+
+func setupValidate(flags *flag.FlagSet) func(args []string) error {
+	addMapPathFlag(flags)
+	return func(args []string) error {
+		if len(args) == 0 {
+			return usageError("validate needs at least one map")
+		}
+		invalid := 0
+		for _, name := range args {
+			terrainMap, err := readTerrainMap(name)
+			if err != nil {
+				printError(err)
+				invalid++
+				continue
+			}
+			fmt.Println("OK", terrainMap.source.path)
+		}
+		if invalid > 0 {
+			return fmt.Errorf("%d of %d maps are not valid", invalid, len(args))
+		}
+		return nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//======================================convert/resolve=======================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+func setupConvert(flags *flag.FlagSet) func(args []string) error {
+	addMapPathFlag(flags)
+	formatName := addMapFormatFlag(flags)
+	return func(args []string) error {
+		if len(args) < 1 || len(args) > 2 {
+			return usageError("convert needs a map and at most one output file")
+		}
+		// Only valid maps are converted, so a map that fails to convert is not mistaken for one that converted as it was
+		if _, err := readTerrainMap(args[0]); err != nil {
+			return err
+		}
+		// The map is converted as it is written, a map that extends another still extends it
+		_, m, err := readTerrainMapFields(args[0])
+		if err != nil {
+			return err
+		}
+		return writeMapFields(m, *formatName, args[1:])
+	}
+}
+
+func setupResolve(flags *flag.FlagSet) func(args []string) error {
+	addMapPathFlag(flags)
+	formatName := addMapFormatFlag(flags)
+	return func(args []string) error {
+		if len(args) < 1 || len(args) > 2 {
+			return usageError("resolve needs a map and at most one output file")
+		}
+		resolved, err := resolveTerrainMap(args[0])
+		if err != nil {
+			return err
+		}
+		if err := writeMapFields(resolved.fields, *formatName, args[1:]); err != nil {
+			return err
+		}
+		// The resolved map is written even if it is not valid, since it shows where its problems came from
+		_, err = decodeTerrainMap(resolved)
+		return err
+	}
+}
+
+// Adds the -format flag of the commands that write maps
+func addMapFormatFlag(flags *flag.FlagSet) *string {
+	return flags.String("format", "", "The format of the written map, json, yaml or toml, by default the extension of the output file or json when it is written to stdout")
+}
+
+/*
+ * Writes the fields of a map to a file, or to stdout
+ * @param m The fields of the map
+ * @param formatName The format of the map, or "" for the format of the extension of the output file
+ * @param output The path of the output file, or nothing to write the map to stdout
+ */
+func writeMapFields(m map[string]interface{}, formatName string, output []string) error {
+	format := mapFormatJSON
+	if len(output) == 1 {
+		var ok bool
+		if format, ok = mapFormatOf(output[0]); !ok && formatName == "" {
+			return fmt.Errorf("unknown map format %q, expected one of %s or -format", filepath.Ext(output[0]), mapExtensions())
+		}
+	}
+	if formatName != "" {
+		var err error
+		if format, err = parseMapFormat(formatName); err != nil {
+			return usageError(err.Error())
+		}
+	}
+
+	data, err := format.marshal(m)
+	if err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(output[0], data, 0644)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//===========================================stats============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The number of bars of the histogram printed by stats
+const histogramBins = 10
+
+func setupStats(flags *flag.FlagSet) func(args []string) error {
+	mf := addMapFlags(flags)
+	return func(args []string) error {
+		if len(args) != 1 {
+			return usageError("stats needs exactly one map")
+		}
+		surface, _, err := mf.build(args[0])
+		if err != nil {
+			return err
+		}
+		printHeightStats(os.Stdout, surface.HeightField())
+		return nil
+	}
+}
+
+/*
+ * Prints the range, mean, standard deviation and a histogram of the heights of a height field
+ * @param w The writer the statistics are printed to
+ * @param field The height field
+ */
+func printHeightStats(w io.Writer, field *terrain.HeightField) {
+	heights := field.Heights()
+	low, high := field.Range()
+	sum := float64(0)
+	for _, h := range heights {
+		sum += float64(h)
+	}
+	mean := sum / float64(len(heights))
+	variance := float64(0)
+	for _, h := range heights {
+		variance += (float64(h) - mean) * (float64(h) - mean)
+	}
+	variance /= float64(len(heights))
+
+	extent := field.Extent()
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "vertices\t%d x %d\n", field.Width(), field.Height())
+	fmt.Fprintf(tw, "extent\tx %g to %g, y %g to %g\n", extent.MinX, extent.MaxX, extent.MinY, extent.MaxY)
+	fmt.Fprintf(tw, "min\t%g\n", low)
+	fmt.Fprintf(tw, "max\t%g\n", high)
+	fmt.Fprintf(tw, "mean\t%g\n", mean)
+	fmt.Fprintf(tw, "std dev\t%g\n", math.Sqrt(variance))
+	tw.Flush()
+
+	var bins [histogramBins]int
+	for _, h := range heights {
+		bin := 0
+		if high > low {
+			bin = int(float64(h-low) / float64(high-low) * histogramBins)
+		}
+		if bin >= histogramBins {
+			bin = histogramBins - 1
+		}
+		bins[bin]++
+	}
+	most := 0
+	for _, count := range bins {
+		if count > most {
+			most = count
+		}
+	}
+	fmt.Fprintln(w, "\nhistogram")
+	for i, count := range bins {
+		from := float64(low) + float64(high-low)*float64(i)/histogramBins
+		to := float64(low) + float64(high-low)*float64(i+1)/histogramBins
+		fmt.Fprintf(w, "%10.4f to %10.4f  %6.2f%%  %s\n", from, to, 100*float64(count)/float64(len(heights)), strings.Repeat("#", count*40/most))
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//===========================================batch============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+func setupBatch(flags *flag.FlagSet) func(args []string) error {
+	addMapPathFlag(flags)
+	return func(args []string) error {
+		if len(args) != 1 {
+			return usageError("batch needs exactly one jobs file")
+		}
+		var r io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		return runBatch(args[0], r)
+	}
+}
+
+/*
+ * Runs the export command for every line of a jobs file. A line holds the arguments of one export, separated by spaces,
+ * and blank lines and lines starting with # are skipped. Every job runs even if one before it fails.
+ * @param name The name of the jobs file, used in the messages of failed jobs
+ * @param r The reader of the jobs file
+ */
+func runBatch(name string, r io.Reader) error {
+	export, _ := findCommand("export")
+	scanner := bufio.NewScanner(r)
+	jobs, failed := 0, 0
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		jobs++
+		// Every job starts from the search path of the batch, even if the job before it changed it with -maps
+		batchMapPath := mapPath
+		err := runCommand(export, strings.Fields(text))
+		mapPath = batchMapPath
+		if err != nil {
+			printError(fmt.Errorf("%s:%d: %v", name, line, err))
+			failed++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d jobs failed", failed, jobs)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//============================================help============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+func setupHelp(flags *flag.FlagSet) func(args []string) error {
+	return func(args []string) error {
+		if len(args) == 0 {
+			printUsage(os.Stdout)
+			return nil
+		}
+		cmd, ok := findCommand(args[0])
+		if !ok {
+			return usageError(fmt.Sprintf("unknown command %q", args[0]))
+		}
+		flags, _ := newCommandFlags(cmd)
+		flags.SetOutput(os.Stdout)
+		flags.Usage()
+		return nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//============================================main============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+/*
+ * Runs the original form of the command line, where the arguments are a map, then optionally the terrain width and height
+ * and any output files. Without output files the terrain is opened in the viewer.
+ * @param args The command line arguments
+ */
+func runLegacy(args []string) error {
+	flags := flag.NewFlagSet("terrain-generation", flag.ContinueOnError)
+	flags.Usage = func() { printUsage(flags.Output()) }
+	addMapPathFlag(flags)
+	of := addOutputFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usageError("")
+	}
+	args = flags.Args()
+	if len(args) != 1 && len(args) < 3 {
+		printUsage(os.Stderr)
+		return usageError("need to pass in 1 command line arguement, or 3 followed by any output files")
+	}
+
+	mf := &mapFlags{flags: flags, width: 124, height: 124}
+	if len(args) >= 3 {
+		width, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return usageError(fmt.Sprintf("the terrain width %q is not a number of vertices", args[1]))
+		}
+		height, err := strconv.ParseUint(args[2], 10, 32)
+		if err != nil {
+			return usageError(fmt.Sprintf("the terrain height %q is not a number of vertices", args[2]))
+		}
+		mf.width, mf.height = uint(width), uint(height)
+	}
+	surface, terrainMap, err := mf.build(args[0])
+	if err != nil {
+		return err
+	}
+	if len(args) <= 3 {
+		return viewTerrain(surface)
+	}
+	return writeTerrain(surface, terrainMap, args[3:], of)
+}
+
+/*
+ * Prints an error, every problem of a map file on its own line. Errors without a message have already been printed.
+ * @param err The error to print
+ */
+func printError(err error) {
+	if err.Error() == "" {
+		return
+	}
+	for _, line := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintln(os.Stderr, "Error!", line)
+	}
+}
+
+/*
+ * Runs the command line of the program and returns its exit status: 0 when it succeeds, 1 when a command fails and 2 when
+ * its arguments are wrong
+ * @param args The command line arguments after the name of the program
+ */
+func run(args []string) int {
+	var err error
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return 2
+	} else if cmd, ok := findCommand(args[0]); ok {
+		err = runCommand(cmd, args[1:])
+	} else {
+		err = runLegacy(args)
+	}
+	if err != nil {
+		printError(err)
+		var usage usageError
+		if errors.As(err, &usage) {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// The first argument is the command to run, see printUsage. Arguments that do not start with a command are the original
+// form of the command line: flags, a map, the terrain width and height (the number of vertices sampled in each direction,
+// any width and height of at least 2 work for any number of gradients, see SamplingGrid) and any output files.
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The exit status of the command line is 0 when it succeeds, 1 when a command fails and 2 when its arguments are wrong
+func TestRunExitStatus(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "fractal.json")
+	cases := []struct {
+		args   []string
+		status int
+	}{
+		{nil, 2},
+		{[]string{"help"}, 0},
+		{[]string{"help", "export"}, 0},
+		{[]string{"help", "nope"}, 2},
+		{[]string{"export"}, 2},
+		{[]string{"export", "fractal_test"}, 2},
+		{[]string{"export", "-seed", "abc", "fractal_test", out}, 2},
+		{[]string{"export", "-seed1", "1.5", "fractal_test", out}, 2},
+		{[]string{"export", "-width", "abc", "fractal_test", out}, 2},
+		{[]string{"export", "-width", "-5", "fractal_test", out}, 2},
+		{[]string{"export", "-width", "1", "fractal_test", out}, 2},
+		{[]string{"export", "-nope", "fractal_test", out}, 2},
+		{[]string{"export", "-width", "9", "-height", "9", "-seed", "7", "fractal_test", out}, 0},
+		{[]string{"export", "-width", "9", "-height", "9", "no_such_map", out}, 1},
+		{[]string{"validate"}, 2},
+		{[]string{"validate", "fractal_test", "layered_test"}, 0},
+		{[]string{"validate", "fractal_test", "no_such_map"}, 1},
+		{[]string{"stats", "-width", "9", "-height", "9", "fractal_test"}, 0},
+		// An unknown command is read as a map by the original form of the command line
+		{[]string{"nope", "9", "9", out}, 1},
+		{[]string{"fractal_test", "abc", "9", out}, 2},
+		{[]string{"fractal_test", "9"}, 2},
+		{[]string{"fractal_test", "9", "9", out}, 0},
+	}
+	for _, c := range cases {
+		if status := run(c.args); status != c.status {
+			t.Errorf("%q exits with status %d, expected %d", c.args, status, c.status)
+		}
+	}
+}
+
+// A map that fails validation exits with a non-zero status, and only after every map is checked
+func TestRunValidateInvalid(t *testing.T) {
+	dir := writeTestMaps(t, map[string]string{
+		"good.json": `{"typ": 1, "gradient_width_b1": 5, "gradient_height_b1": 5, "seed1": 43, "m": 1}`,
+		"bad.json":  `{"typ": 1, "gradient_width_b1": 4, "gradient_height_b1": 5, "seed1": 43, "m": 1}`,
+	})
+	defer func(path string) { mapPath = path }(mapPath)
+	if status := run([]string{"validate", "-maps", dir, "bad", "good"}); status != 1 {
+		t.Errorf("validating an invalid map exits with status %d, expected 1", status)
+	}
+	if status := run([]string{"validate", "-maps", dir, "good"}); status != 0 {
+		t.Errorf("validating a valid map exits with status %d, expected 0", status)
+	}
+}
+
+// Every job of a batch starts from the search path of the batch, so -maps in one job does not change where the next
+// job reads its map from
+func TestRunBatchMapPath(t *testing.T) {
+	dir := writeTestMaps(t, map[string]string{
+		"custom.json": `{"typ": 1, "gradient_width_b1": 5, "gradient_height_b1": 5, "seed1": 43, "m": 1}`,
+	})
+	out := t.TempDir()
+	defer func(path string) { mapPath = path }(mapPath)
+	mapPath = filepath.Join(out, "no_maps_here")
+
+	jobs := strings.Join([]string{
+		"# the first job finds custom on its own search path",
+		"-maps " + dir + " -width 9 -height 9 custom " + filepath.Join(out, "first.json"),
+		"",
+		"-width 9 -height 9 custom " + filepath.Join(out, "second.json"),
+	}, "\n")
+	err := runBatch("jobs", strings.NewReader(jobs))
+	if err == nil || err.Error() != "1 of 2 jobs failed" {
+		t.Errorf("the batch returned %v, expected the second job to fail", err)
+	}
+	if mapPath != filepath.Join(out, "no_maps_here") {
+		t.Errorf("the search path after the batch is %q, expected it to be restored", mapPath)
+	}
+	if _, err := os.Stat(filepath.Join(out, "first.json")); err != nil {
+		t.Errorf("the first job did not write its terrain: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "second.json")); err == nil {
+		t.Error("the second job read custom from the search path of the job before it")
+	}
+}
@@ -0,0 +1,547 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"terrain-generation/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//========================================ConfigError=========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// A problem with a map file
+type ConfigError struct {
+	// The path of the map file
+	File string
+	// The field of the map with the problem, e.g. layers[2].blend, or empty for a problem with the whole file
+	Field string
+	// What is wrong with the field
+	Message string
+}
+
+func (err ConfigError) Error() string {
+	if err.Field == "" {
+		return fmt.Sprintf("%s: %s", err.File, err.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", err.File, err.Field, err.Message)
+}
+
+// Every problem found in a map file, in the order they were found
+type ConfigErrors []ConfigError
+
+func (errs ConfigErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=========================================mapDecoder=========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// Converts the values of a map file into the types of the terrain map, collecting a ConfigError for every value
+// that does not convert instead of stopping at the first one
+type mapDecoder struct {
+	file string
+	// The files the fields of a map were read from when it extends other maps, by field
+	origins map[string]mapSource
+	errs    ConfigErrors
+}
+
+/*
+ * Records a problem with a field of the map file
+ * @param field The field with the problem
+ * @param format The format of the message, followed by its arguments
+ */
+func (decoder *mapDecoder) fail(field, format string, args ...interface{}) {
+	file := decoder.file
+	key := field
+	if i := strings.IndexAny(field, ".["); i >= 0 {
+		key = field[:i]
+	}
+	if origin, ok := decoder.origins[key]; ok {
+		file = origin.path
+	}
+	decoder.errs = append(decoder.errs, ConfigError{File: file, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Whether a problem has already been recorded with a field of the map file
+func (decoder *mapDecoder) failed(field string) bool {
+	for _, err := range decoder.errs {
+		if err.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Converts a number, recording a problem if the value is not a number or not a whole number in [low, high]
+ * @param field The field of the value
+ * @param v The value
+ * @param whole Whether the number needs to be a whole number
+ * @param low The lowest number the field can hold
+ * @param high The highest number the field can hold
+ */
+func (decoder *mapDecoder) toNumber(field string, v interface{}, whole bool, low, high float64) (float64, bool) {
+	n, ok := v.(float64)
+	if !ok {
+		decoder.fail(field, "%s, expected a number", describeValue(v))
+		return 0, false
+	}
+	if whole && n != math.Trunc(n) {
+		decoder.fail(field, "%v is not a whole number", n)
+		return 0, false
+	}
+	if n < low || n > high {
+		decoder.fail(field, "%v is outside of [%v, %v]", n, low, high)
+		return 0, false
+	}
+	return n, true
+}
+
+func (decoder *mapDecoder) toUint32(field string, v interface{}) uint32 {
+	n, _ := decoder.toNumber(field, v, true, 0, math.MaxUint32)
+	return uint32(n)
+}
+
+func (decoder *mapDecoder) toInt32(field string, v interface{}) int32 {
+	n, _ := decoder.toNumber(field, v, true, math.MinInt32, math.MaxInt32)
+	return int32(n)
+}
+
+func (decoder *mapDecoder) toFloat32(field string, v interface{}) float32 {
+	n, _ := decoder.toNumber(field, v, false, -math.MaxFloat32, math.MaxFloat32)
+	return float32(n)
+}
+
+/*
+ * Converts a number of octaves, which has to pass terrain.CheckOctaves
+ * @param field The field of the value
+ * @param v The value
+ */
+func (decoder *mapDecoder) toOctaves(field string, v interface{}) uint32 {
+	n, ok := decoder.toNumber(field, v, true, 0, math.MaxUint32)
+	if !ok {
+		// The default, so the octaves are not reported again by the checks that use them
+		return 1
+	}
+	if err := terrain.CheckOctaves(uint32(n)); err != nil {
+		decoder.fail(field, "%v", err)
+		return 1
+	}
+	return uint32(n)
+}
+
+/*
+ * Converts a number that has to pass terrain.CheckMultiplier, like the multipliers of fractals and the frequency of warps
+ * @param field The field of the value
+ * @param v The value
+ * @param fallback The default of the field, returned when the value is not converted so it is not reported again
+ */
+func (decoder *mapDecoder) toPositiveFloat32(field string, v interface{}, fallback float32) float32 {
+	n, ok := decoder.toNumber(field, v, false, -math.MaxFloat32, math.MaxFloat32)
+	if !ok {
+		return fallback
+	}
+	if err := terrain.CheckMultiplier(float32(n)); err != nil {
+		decoder.fail(field, "%v", err)
+		return fallback
+	}
+	return float32(n)
+}
+
+func (decoder *mapDecoder) toString(field string, v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		decoder.fail(field, "%s, expected a string", describeValue(v))
+	}
+	return s
+}
+
+func (decoder *mapDecoder) toBool(field string, v interface{}) bool {
+	b, ok := v.(bool)
+	if !ok {
+		decoder.fail(field, "%s, expected true or false", describeValue(v))
+	}
+	return b
+}
+
+/*
+ * Describes the type of a value for the messages of wrongly typed fields, as encoding/json or unmarshal decodes it
+ * @param v The value
+ */
+func describeValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return fmt.Sprintf("%v is a boolean", v)
+	case float64:
+		return fmt.Sprintf("%v is a number", v)
+	case string:
+		return fmt.Sprintf("%q is a string", v)
+	case []interface{}:
+		return "an array"
+	}
+	return "an object"
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//==========================================Decoding==========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+/*
+ * Parses a map file into its fields in the format of its extension, json, yaml or toml
+ * @param file The path of the map file, which picks its format and is used in the messages of its problems
+ * @param data The contents of the map file
+ */
+func parseTerrainMap(file string, data []byte) (map[string]interface{}, error) {
+	decoder := &mapDecoder{file: file}
+	format, ok := mapFormatOf(file)
+	if !ok {
+		decoder.fail("", "unknown map format %q, expected one of %s", filepath.Ext(file), mapExtensions())
+		return nil, decoder.errs
+	}
+	i, err := format.unmarshal(data)
+	if err != nil {
+		decoder.fail("", "invalid %s: %v", format, err)
+		return nil, decoder.errs
+	}
+	m, ok := i.(map[string]interface{})
+	if !ok {
+		decoder.fail("", "%s, a map is an object", describeValue(i))
+		return nil, decoder.errs
+	}
+	return m, nil
+}
+
+/*
+ * Deconstructs the fields of a map into a terrain map and validates it. Keys that are missing from the map keep their
+ * defaults. Every problem with the map is returned together as ConfigErrors, each with the file its field was read from.
+ * @param resolved The fields of the map merged with the maps it extends, see resolveTerrainMap
+ */
+func decodeTerrainMap(resolved resolvedMap) (TerrainMap, error) {
+	decoder := &mapDecoder{file: resolved.source.path, origins: resolved.origins}
+	m := resolved.fields
+	terrainMap := TerrainMap{distance_b1: "f1", distance_b2: "f1", metric_b1: "euclidean", metric_b2: "euclidean", octaves: 1, lacunarity: 2, persistence: 0.5, fractal: "fbm", ridge_offset: 1, gain: 2, sharpness: 2,
+		warp_frequency: 1, warp_iterations: 1}
+	for _, k := range sortedKeys(m) {
+		v := m[k]
+		switch k {
+		case "typ":
+			typ, _ := decoder.toNumber(k, v, true, 0, math.MaxUint8)
+			terrainMap.typ = uint8(typ)
+		case "gradient_width_b1":
+			terrainMap.gradient_width_b1 = decoder.toUint32(k, v)
+		case "gradient_height_b1":
+			terrainMap.gradient_height_b1 = decoder.toUint32(k, v)
+		case "gradient_width_b2":
+			terrainMap.gradient_width_b2 = decoder.toUint32(k, v)
+		case "gradient_height_b2":
+			terrainMap.gradient_height_b2 = decoder.toUint32(k, v)
+		case "seed1":
+			terrainMap.seed1 = decoder.toInt32(k, v)
+		case "seed2":
+			terrainMap.seed2 = decoder.toInt32(k, v)
+		case "noise_b1":
+			terrainMap.noise_b1 = decoder.toString(k, v)
+		case "noise_b2":
+			terrainMap.noise_b2 = decoder.toString(k, v)
+		case "distance_b1":
+			terrainMap.distance_b1 = decoder.toString(k, v)
+		case "distance_b2":
+			terrainMap.distance_b2 = decoder.toString(k, v)
+		case "metric_b1":
+			terrainMap.metric_b1 = decoder.toString(k, v)
+		case "metric_b2":
+			terrainMap.metric_b2 = decoder.toString(k, v)
+		case "m":
+			terrainMap.m = decoder.toFloat32(k, v)
+		case "prop":
+			terrainMap.prop = decoder.toFloat32(k, v)
+		case "octaves":
+			terrainMap.octaves = decoder.toOctaves(k, v)
+		case "lacunarity":
+			terrainMap.lacunarity = decoder.toPositiveFloat32(k, v, 2)
+		case "persistence":
+			terrainMap.persistence = decoder.toPositiveFloat32(k, v, 0.5)
+		case "fractal":
+			terrainMap.fractal = decoder.toString(k, v)
+		case "ridge_offset":
+			terrainMap.ridge_offset = decoder.toFloat32(k, v)
+		case "gain":
+			terrainMap.gain = decoder.toFloat32(k, v)
+		case "sharpness":
+			terrainMap.sharpness = decoder.toFloat32(k, v)
+		case "warp_strength":
+			terrainMap.warp_strength = decoder.toFloat32(k, v)
+		case "warp_seed":
+			terrainMap.warp_seed = decoder.toInt32(k, v)
+		case "warp_frequency":
+			terrainMap.warp_frequency = decoder.toPositiveFloat32(k, v, 1)
+		case "warp_iterations":
+			terrainMap.warp_iterations = decoder.toUint32(k, v)
+		case "warp_noise":
+			terrainMap.warp_noise = decoder.toString(k, v)
+		case "periodic":
+			terrainMap.periodic = decoder.toBool(k, v)
+		case "layers":
+			layers, ok := v.([]interface{})
+			if !ok {
+				decoder.fail(k, "%s, expected an array of layers", describeValue(v))
+				continue
+			}
+			for j, l := range layers {
+				field := fmt.Sprintf("layers[%d]", j)
+				layer, ok := l.(map[string]interface{})
+				if !ok {
+					decoder.fail(field, "%s, a layer is an object", describeValue(l))
+					continue
+				}
+				terrainMap.layers = append(terrainMap.layers, decodeTerrainMapLayer(decoder, field, layer))
+			}
+		default:
+			decoder.fail(k, "unknown field")
+		}
+	}
+
+	terrainMap.source = resolved.source
+	terrainMap.layerSource = resolved.source
+	if origin, ok := resolved.origins["layers"]; ok {
+		terrainMap.layerSource = origin
+	}
+
+	validateTerrainMap(decoder, terrainMap, m)
+	if len(decoder.errs) > 0 {
+		return terrainMap, decoder.errs
+	}
+	return terrainMap, nil
+}
+
+/*
+ * Deconstructs one entry of the layers of a map file into a terrain map layer. Layers are single octave fbm layers
+ * added with a weight of 1 unless the entry says otherwise.
+ * @param decoder The decoder of the map file
+ * @param prefix The field of the layer in the map file, e.g. layers[2]
+ * @param m The fields of the layer
+ */
+func decodeTerrainMapLayer(decoder *mapDecoder, prefix string, m map[string]interface{}) TerrainMapLayer {
+	layer := TerrainMapLayer{weight: 1, blend: "add", mask: -1, distance: "f1", metric: "euclidean",
+		fractal: "fbm", octaves: 1, lacunarity: 2, persistence: 0.5, ridge_offset: 1, gain: 2, sharpness: 2}
+	for _, k := range sortedKeys(m) {
+		v := m[k]
+		field := prefix + "." + k
+		switch k {
+		case "gradient_width":
+			layer.gradient_width = decoder.toUint32(field, v)
+		case "gradient_height":
+			layer.gradient_height = decoder.toUint32(field, v)
+		case "seed":
+			layer.seed = decoder.toInt32(field, v)
+		case "noise":
+			layer.noise = decoder.toString(field, v)
+		case "distance":
+			layer.distance = decoder.toString(field, v)
+		case "metric":
+			layer.metric = decoder.toString(field, v)
+		case "fractal":
+			layer.fractal = decoder.toString(field, v)
+		case "octaves":
+			layer.octaves = decoder.toOctaves(field, v)
+		case "lacunarity":
+			layer.lacunarity = decoder.toPositiveFloat32(field, v, 2)
+		case "persistence":
+			layer.persistence = decoder.toPositiveFloat32(field, v, 0.5)
+		case "ridge_offset":
+			layer.ridge_offset = decoder.toFloat32(field, v)
+		case "gain":
+			layer.gain = decoder.toFloat32(field, v)
+		case "sharpness":
+			layer.sharpness = decoder.toFloat32(field, v)
+		case "weight":
+			layer.weight = decoder.toFloat32(field, v)
+		case "offset":
+			layer.offset = decoder.toFloat32(field, v)
+		case "blend":
+			layer.blend = decoder.toString(field, v)
+		case "mask":
+			layer.mask = int(decoder.toInt32(field, v))
+		case "heightmap":
+			layer.heightmap = decoder.toString(field, v)
+		case "heightmap_width":
+			layer.heightmap_width = decoder.toUint32(field, v)
+		case "heightmap_height":
+			layer.heightmap_height = decoder.toUint32(field, v)
+		default:
+			decoder.fail(field, "unknown field")
+		}
+	}
+	return layer
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=========================================Validation=========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+/*
+ * Records every problem with the values of a decoded terrain map that would stop it from generating the terrain it describes
+ * @param decoder The decoder of the map file
+ * @param terrainMap The decoded terrain map
+ * @param keys The fields of the map file, to tell fields that are missing from fields that are 0
+ */
+func validateTerrainMap(decoder *mapDecoder, terrainMap TerrainMap, keys map[string]interface{}) {
+	require := func(fields ...string) {
+		for _, field := range fields {
+			if _, ok := keys[field]; !ok {
+				decoder.fail(field, "missing, typ %d maps need it", terrainMap.typ)
+			}
+		}
+	}
+	switch terrainMap.typ {
+	case 1, 3:
+		require("gradient_width_b1", "gradient_height_b1", "seed1")
+	case 2:
+		require("gradient_width_b1", "gradient_height_b1", "gradient_width_b2", "gradient_height_b2", "seed1", "seed2")
+		if terrainMap.prop < 0 || terrainMap.prop > 1 {
+			decoder.fail("prop", "%v is outside of [0, 1]", terrainMap.prop)
+		}
+	case 4:
+		if len(terrainMap.layers) == 0 {
+			decoder.fail("layers", "a typ 4 map needs at least one layer")
+		}
+	default:
+		if _, ok := keys["typ"]; !ok {
+			decoder.fail("typ", "missing")
+		} else if !decoder.failed("typ") {
+			// A typ that did not decode has already been reported, its value here is not the one in the map
+			decoder.fail("typ", "%d is not 1 (simple), 2 (bipartite), 3 (fractal) or 4 (layered)", terrainMap.typ)
+		}
+	}
+
+	for _, field := range []string{"gradient_width_b1", "gradient_height_b1", "gradient_width_b2", "gradient_height_b2"} {
+		if v, ok := keys[field].(float64); ok {
+			validateGradientSize(decoder, field, v)
+		}
+	}
+	validateNoise(decoder, "noise_b1", terrainMap.noise_b1, terrainMap.periodic)
+	validateNoise(decoder, "noise_b2", terrainMap.noise_b2, terrainMap.periodic)
+	validateNoise(decoder, "warp_noise", terrainMap.warp_noise, terrainMap.periodic)
+	validateWorley(decoder, "distance_b1", "metric_b1", terrainMap.distance_b1, terrainMap.metric_b1)
+	validateWorley(decoder, "distance_b2", "metric_b2", terrainMap.distance_b2, terrainMap.metric_b2)
+	if _, ok := terrain.ParseFractalStyle(terrainMap.fractal); !ok {
+		decoder.fail("fractal", "unknown fractal style %q, expected fbm, ridged or billow", terrainMap.fractal)
+	}
+	if terrainMap.typ == 3 {
+		if err := checkPeriodicFractal(terrainMap.periodic, terrainMap.gradient_width_b1, terrainMap.gradient_height_b1, terrainMap.octaves, terrainMap.lacunarity); err != nil {
+			decoder.fail("lacunarity", "%v", err)
+		}
+	}
+	// The warp repeats with the first board of the map, or the first layer of a layered map
+	warpWidth, warpHeight := terrainMap.gradient_width_b1, terrainMap.gradient_height_b1
+	if terrainMap.typ == 4 && len(terrainMap.layers) > 0 {
+		warpWidth, warpHeight = terrainMap.layers[0].gradient_width, terrainMap.layers[0].gradient_height
+	}
+	if err := checkPeriodicWarp(terrainMap, warpWidth, warpHeight); err != nil {
+		decoder.fail("warp_frequency", "%v", err)
+	}
+
+	for i, layer := range terrainMap.layers {
+		prefix := fmt.Sprintf("layers[%d]", i)
+		validateGradientSize(decoder, prefix+".gradient_width", float64(layer.gradient_width))
+		validateGradientSize(decoder, prefix+".gradient_height", float64(layer.gradient_height))
+		blend, ok := terrain.ParseBlend(layer.blend)
+		if !ok {
+			decoder.fail(prefix+".blend", "unknown blend %q, expected add, multiply, max, min, lerp or none", layer.blend)
+		}
+		if blend == terrain.BlendLerp && (layer.mask < 0 || layer.mask >= len(terrainMap.layers)) {
+			decoder.fail(prefix+".mask", "%d is not the index of one of the %d layers", layer.mask, len(terrainMap.layers))
+		}
+		if layer.heightmap != "" {
+			switch strings.ToLower(filepath.Ext(layer.heightmap)) {
+			case ".png", ".r16", ".r32":
+			default:
+				decoder.fail(prefix+".heightmap", "%q is not a png, r16 or r32 heightmap", layer.heightmap)
+			}
+			if terrainMap.periodic {
+				decoder.fail(prefix+".heightmap", "a heightmap can not be periodic")
+			}
+			if (layer.heightmap_width == 0) != (layer.heightmap_height == 0) {
+				decoder.fail(prefix+".heightmap_width", "heightmap_width and heightmap_height are set together, or neither for a square heightmap")
+			}
+			continue
+		}
+		validateNoise(decoder, prefix+".noise", layer.noise, terrainMap.periodic)
+		validateWorley(decoder, prefix+".distance", prefix+".metric", layer.distance, layer.metric)
+		if _, ok := terrain.ParseFractalStyle(layer.fractal); !ok {
+			decoder.fail(prefix+".fractal", "unknown fractal style %q, expected fbm, ridged or billow", layer.fractal)
+		}
+		if err := checkPeriodicFractal(terrainMap.periodic, layer.gradient_width, layer.gradient_height, layer.octaves, layer.lacunarity); err != nil {
+			decoder.fail(prefix+".lacunarity", "%v", err)
+		}
+	}
+}
+
+// The largest gradient width or height, gradient boards place their cells with int32 coordinates
+const maxGradientSize = math.MaxInt32
+
+/*
+ * Records a problem with a gradient width or height that is not an odd number in [3, maxGradientSize]
+ * @param decoder The decoder of the map file
+ * @param field The field of the gradient width or height
+ * @param size The gradient width or height
+ */
+func validateGradientSize(decoder *mapDecoder, field string, size float64) {
+	if size < 3 {
+		decoder.fail(field, "%v is too small, gradient sizes need to be odd numbers of at least 3", size)
+	} else if size > maxGradientSize {
+		decoder.fail(field, "%v is too large, gradient sizes can be at most %d", size, maxGradientSize)
+	} else if math.Mod(size, 2) == 0 {
+		decoder.fail(field, "%v is even, gradient sizes need to be odd numbers", size)
+	}
+}
+
+/*
+ * Records a problem with the name of a noise backend that is unknown or that can not be periodic
+ * @param decoder The decoder of the map file
+ * @param field The field of the noise backend
+ * @param noise The name of the noise backend
+ * @param periodic Whether the map is periodic
+ */
+func validateNoise(decoder *mapDecoder, field, noise string, periodic bool) {
+	if _, ok := terrain.ParseNoiseBackend(noise); !ok {
+		decoder.fail(field, "unknown noise backend %q, expected perlin, simplex or worley", noise)
+	} else if err := checkPeriodic(periodic, noise); err != nil {
+		decoder.fail(field, "%v", err)
+	}
+}
+
+/*
+ * Records a problem with the names of the feature point distance and distance metric of a worley board that are unknown
+ * @param decoder The decoder of the map file
+ * @param distanceField The field of the feature point distance
+ * @param metricField The field of the distance metric
+ * @param distance The name of the feature point distance
+ * @param metric The name of the distance metric
+ */
+func validateWorley(decoder *mapDecoder, distanceField, metricField, distance, metric string) {
+	if _, ok := terrain.ParseWorleyDistance(distance); !ok {
+		decoder.fail(distanceField, "unknown worley distance %q, expected f1, f2 or f2-f1", distance)
+	}
+	if _, ok := terrain.ParseDistanceMetric(metric); !ok {
+		decoder.fail(metricField, "unknown distance metric %q, expected euclidean, manhattan or chebyshev", metric)
+	}
+}
+
+// The keys of an object in alphabetical order, so its problems are reported in the same order every time
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
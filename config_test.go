@@ -0,0 +1,132 @@
+package main
+
+import (
+	"testing"
+)
+
+// The fields of a fractal map, which the cases of the decoder tests change
+func fractalTestFields() map[string]interface{} {
+	return map[string]interface{}{"typ": float64(3), "gradient_width_b1": float64(5), "gradient_height_b1": float64(5),
+		"seed1": float64(43), "m": float64(1)}
+}
+
+// Octaves past terrain.MaxOctaves and multipliers that are not above 0 are rejected with an error naming their field
+func TestDecodeFractalBounds(t *testing.T) {
+	cases := []struct {
+		field string
+		value interface{}
+	}{
+		{"octaves", float64(1000000000)},
+		{"octaves", float64(17)},
+		{"octaves", float64(0)},
+		{"lacunarity", float64(0)},
+		{"persistence", float64(-0.5)},
+		{"warp_frequency", float64(0)},
+	}
+	for _, c := range cases {
+		fields := fractalTestFields()
+		fields[c.field] = c.value
+		_, err := decodeTerrainMap(resolvedMap{fields: fields})
+		errs, ok := err.(ConfigErrors)
+		if !ok || len(errs) != 1 || errs[0].Field != c.field {
+			t.Errorf("%s %v is reported as %v, expected a single problem with %s", c.field, c.value, err, c.field)
+		}
+	}
+
+	fields := fractalTestFields()
+	fields["octaves"] = float64(16)
+	if _, err := decodeTerrainMap(resolvedMap{fields: fields}); err != nil {
+		t.Errorf("16 octaves are rejected: %v", err)
+	}
+}
+
+// The distance and metric of a worley macro board are decoded and change the heights of its terrain
+func TestDecodeWorleyBoard(t *testing.T) {
+	fields := map[string]interface{}{"typ": float64(1), "gradient_width_b1": float64(7), "gradient_height_b1": float64(7),
+		"seed1": float64(71), "noise_b1": "worley", "m": float64(1)}
+	defaults, err := decodeTerrainMap(resolvedMap{fields: fields})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields["distance_b1"] = "f2-f1"
+	fields["metric_b1"] = "manhattan"
+	cracks, err := decodeTerrainMap(resolvedMap{fields: fields})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cracks.distance_b1 != "f2-f1" || cracks.metric_b1 != "manhattan" {
+		t.Fatalf("distance_b1 and metric_b1 decoded to %q and %q", cracks.distance_b1, cracks.metric_b1)
+	}
+
+	a, err := buildSimpleTerrain(defaults, 33, 33)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := buildSimpleTerrain(cracks, 33, 33)
+	if err != nil {
+		t.Fatal(err)
+	}
+	same := true
+	for i, h := range a.HeightField().Heights() {
+		if h != b.HeightField().Heights()[i] {
+			same = false
+			break
+		}
+	}
+	if same {
+		t.Errorf("a worley board with distance_b1 f2-f1 and metric_b1 manhattan has the heights of the default f1 euclidean board")
+	}
+
+	fields["metric_b1"] = "nope"
+	_, err = decodeTerrainMap(resolvedMap{fields: fields})
+	if errs, ok := err.(ConfigErrors); !ok || len(errs) != 1 || errs[0].Field != "metric_b1" {
+		t.Errorf("an unknown metric_b1 is reported as %v, expected a single problem with metric_b1", err)
+	}
+}
+
+// A layer that sets only one of heightmap_width and heightmap_height is rejected
+func TestDecodeHeightmapSize(t *testing.T) {
+	layer := map[string]interface{}{"gradient_width": float64(5), "gradient_height": float64(5), "heightmap": "island.r16",
+		"heightmap_width": float64(64)}
+	fields := map[string]interface{}{"typ": float64(4), "m": float64(1), "layers": []interface{}{layer}}
+	_, err := decodeTerrainMap(resolvedMap{fields: fields})
+	if errs, ok := err.(ConfigErrors); !ok || len(errs) != 1 || errs[0].Field != "layers[0].heightmap_width" {
+		t.Errorf("a heightmap with only heightmap_width is reported as %v, expected a single problem with layers[0].heightmap_width", err)
+	}
+
+	layer["heightmap_height"] = float64(32)
+	if _, err := decodeTerrainMap(resolvedMap{fields: fields}); err != nil {
+		t.Errorf("a 64x32 heightmap is rejected: %v", err)
+	}
+}
+
+// Gradient sizes past the int32 coordinates of a gradient board are rejected with an error naming their field
+func TestDecodeGradientSizeBounds(t *testing.T) {
+	cases := []struct {
+		size  float64
+		valid bool
+	}{
+		{4294967295, false},
+		{2147483649, false},
+		{2147483647, true},
+		{1, false},
+	}
+	for _, c := range cases {
+		fields := fractalTestFields()
+		fields["gradient_height_b1"] = c.size
+		_, err := decodeTerrainMap(resolvedMap{fields: fields})
+		if c.valid && err != nil {
+			t.Errorf("gradient_height_b1 %v is rejected: %v", c.size, err)
+		}
+		if errs, ok := err.(ConfigErrors); !c.valid && (!ok || len(errs) != 1 || errs[0].Field != "gradient_height_b1") {
+			t.Errorf("gradient_height_b1 %v is reported as %v, expected a single problem with gradient_height_b1", c.size, err)
+		}
+	}
+
+	layer := map[string]interface{}{"gradient_width": float64(4294967295), "gradient_height": float64(5)}
+	fields := map[string]interface{}{"typ": float64(4), "m": float64(1), "layers": []interface{}{layer}}
+	_, err := decodeTerrainMap(resolvedMap{fields: fields})
+	if errs, ok := err.(ConfigErrors); !ok || len(errs) != 1 || errs[0].Field != "layers[0].gradient_width" {
+		t.Errorf("a layer gradient_width of 4294967295 is reported as %v, expected a single problem with layers[0].gradient_width", err)
+	}
+}
@@ -0,0 +1,120 @@
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+
+	"terrain-generation/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//============================================DEM=============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The georeferencing of a digital elevation model (dem). The vertices of the height field are the centers of the cells
+// of the dem, and the position of the grid follows the world extent of the field, which moves with the displacement of
+// the terrain, scaled so every cell has the cell size.
+type DEMOptions struct {
+	// The size of a cell on the ground, 0 keeps the spacing of the field's vertices in world coordinates
+	CellSize float64
+	// Added to the position of the lower left cell, to place the grid in a coordinate reference system
+	OriginX, OriginY float64
+	// The EPSG code of the coordinate reference system of the grid, for the formats that record one. 0 leaves it unknown.
+	CRS uint16
+}
+
+/*
+ * Returns the size of a cell in the x and y directions and the position of the center of the lower left cell
+ * @param field The height field of the dem
+ */
+func (options DEMOptions) georeference(field *terrain.HeightField) (float64, float64, float64, float64) {
+	extent := field.Extent()
+	dx, dy := field.Spacing()
+	cellX, cellY := dx, dy
+	x, y := extent.MinX, extent.MinY
+	if options.CellSize > 0 {
+		cellX, cellY = options.CellSize, options.CellSize
+		// The world coordinates are measured in vertices of the field, so they scale the same way as its spacing
+		if dx != 0 {
+			x = extent.MinX / dx * options.CellSize
+		}
+		if dy != 0 {
+			y = extent.MinY / dy * options.CellSize
+		}
+	}
+	return cellX, cellY, options.OriginX + x, options.OriginY + y
+}
+
+// The height written for cells without data, which generated terrain never has
+const noData = -9999
+
+/*
+ * Writes a height field as an ESRI ascii grid. The rows are written from the highest y to the lowest, as the format
+ * requires, and a field whose vertices are not evenly spaced in x and y is written with the dx and dy extension of GDAL.
+ * @param w The writer the grid is written to
+ * @param field The height field to write
+ * @param options The cell size and origin of the grid
+ */
+func WriteASC(w io.Writer, field *terrain.HeightField, options DEMOptions) error {
+	cellX, cellY, x, y := options.georeference(field)
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "ncols %d\nnrows %d\n", field.Width(), field.Height())
+	// The header places the lower left corner of the lower left cell, half a cell from its center
+	fmt.Fprintf(out, "xllcorner %s\nyllcorner %s\n", formatFloat(x-cellX/2), formatFloat(y-cellY/2))
+	if cellX == cellY {
+		fmt.Fprintf(out, "cellsize %s\n", formatFloat(cellX))
+	} else {
+		fmt.Fprintf(out, "dx %s\ndy %s\n", formatFloat(cellX), formatFloat(cellY))
+	}
+	fmt.Fprintf(out, "NODATA_value %d\n", noData)
+	for row := int(field.Height()) - 1; row >= 0; row-- {
+		for col := 0; col < int(field.Width()); col++ {
+			if col > 0 {
+				out.WriteByte(' ')
+			}
+			out.WriteString(strconv.FormatFloat(float64(field.At(col, row)), 'g', -1, 32))
+		}
+		out.WriteByte('\n')
+	}
+	return out.Flush()
+}
+
+/*
+ * Writes the heights of a height field as raw little-endian float32, row by row from the highest y to the lowest.
+ * The dimensions and georeferencing of the heights are written in a sidecar header by WriteR32Header.
+ * @param w The writer the heights are written to
+ * @param field The height field to write
+ */
+func WriteR32(w io.Writer, field *terrain.HeightField) error {
+	out := bufio.NewWriter(w)
+	for row := int(field.Height()) - 1; row >= 0; row-- {
+		start := row * int(field.Width())
+		if err := binary.Write(out, binary.LittleEndian, field.Heights()[start:start+int(field.Width())]); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+/*
+ * Writes the ESRI .hdr sidecar header of the heights written by WriteR32, which GDAL and QGIS read to open the raw
+ * heights as a georeferenced float32 raster
+ * @param w The writer the header is written to
+ * @param field The height field of the heights
+ * @param options The cell size and origin of the heights
+ */
+func WriteR32Header(w io.Writer, field *terrain.HeightField, options DEMOptions) error {
+	cellX, cellY, x, y := options.georeference(field)
+	// The header places the center of the upper left cell
+	top := y + float64(int(field.Height())-1)*cellY
+	_, err := fmt.Fprintf(w, "BYTEORDER I\nLAYOUT BIL\nNROWS %d\nNCOLS %d\nNBANDS 1\nNBITS 32\nPIXELTYPE FLOAT\nNODATA %d\nULXMAP %s\nULYMAP %s\nXDIM %s\nYDIM %s\n",
+		field.Height(), field.Width(), noData, formatFloat(x), formatFloat(top), formatFloat(cellX), formatFloat(cellY))
+	return err
+}
+
+// Formats a float64 without an exponent, with as few digits as it takes to read it back exactly
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
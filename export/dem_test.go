@@ -0,0 +1,127 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"terrain-generation/terrain"
+)
+
+/*
+ * Creates a 3 x 2 height field of the heights 0 to 5 in row major order
+ * @param t The test
+ * @param extent The world coordinates covered by the field
+ */
+func demField(t *testing.T, extent terrain.Extent) *terrain.HeightField {
+	field, err := terrain.NewHeightFieldFromFloat64(3, 2, extent, []float64{0, 1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return field
+}
+
+/*
+ * Reads the keys and values of a header, one pair to a line, and the lines that follow it
+ * @param data The header and the lines that follow it
+ * @param keys The number of lines of the header
+ */
+func readHeader(data []byte, keys int) (map[string]string, []string) {
+	header := map[string]string{}
+	var rest []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(header) < keys && len(fields) == 2 {
+			header[fields[0]] = fields[1]
+		} else {
+			rest = append(rest, scanner.Text())
+		}
+	}
+	return header, rest
+}
+
+// An ascii grid places the corner of its lower left cell half a cell from the first vertex and writes the top row first
+func TestWriteASC(t *testing.T) {
+	tests := []struct {
+		extent  terrain.Extent
+		options DEMOptions
+		header  map[string]string
+	}{
+		{
+			terrain.Extent{MinX: 10, MinY: 20, MaxX: 14, MaxY: 22},
+			DEMOptions{},
+			map[string]string{"ncols": "3", "nrows": "2", "xllcorner": "9", "yllcorner": "19", "cellsize": "2", "NODATA_value": "-9999"},
+		},
+		{
+			// The cell size scales the world coordinates of the vertices, 5 units to the spacing of 2
+			terrain.Extent{MinX: 10, MinY: 20, MaxX: 14, MaxY: 22},
+			DEMOptions{CellSize: 5, OriginX: 100, OriginY: -100},
+			map[string]string{"ncols": "3", "nrows": "2", "xllcorner": "122.5", "yllcorner": "-52.5", "cellsize": "5", "NODATA_value": "-9999"},
+		},
+		{
+			// Cells that are not square are written with dx and dy rather than a cell size
+			terrain.Extent{MinX: 10, MinY: 20, MaxX: 14, MaxY: 23},
+			DEMOptions{},
+			map[string]string{"ncols": "3", "nrows": "2", "xllcorner": "9", "yllcorner": "18.5", "dx": "2", "dy": "3", "NODATA_value": "-9999"},
+		},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := WriteASC(&buf, demField(t, test.extent), test.options); err != nil {
+			t.Fatal(err)
+		}
+		header, rows := readHeader(buf.Bytes(), len(test.header))
+		for key, value := range test.header {
+			if header[key] != value {
+				t.Errorf("%+v %+v: %s is %q, expected %q", test.extent, test.options, key, header[key], value)
+			}
+		}
+		if len(header) != len(test.header) {
+			t.Errorf("%+v %+v: the header is %v, expected %v", test.extent, test.options, header, test.header)
+		}
+		if len(rows) != 2 || rows[0] != "3 4 5" || rows[1] != "0 1 2" {
+			t.Errorf("%+v %+v: the rows are %q, expected the top row 3 4 5 first", test.extent, test.options, rows)
+		}
+	}
+}
+
+// The header of raw heights describes the dimensions, byte order and top row of the heights written by WriteR32
+func TestWriteR32Header(t *testing.T) {
+	field := demField(t, terrain.Extent{MinX: 10, MinY: 20, MaxX: 14, MaxY: 23})
+	var hdr, r32 bytes.Buffer
+	if err := WriteR32Header(&hdr, field, DEMOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteR32(&r32, field); err != nil {
+		t.Fatal(err)
+	}
+
+	header, rest := readHeader(hdr.Bytes(), 12)
+	expected := map[string]string{
+		"BYTEORDER": "I", "LAYOUT": "BIL", "NROWS": "2", "NCOLS": "3", "NBANDS": "1", "NBITS": "32", "PIXELTYPE": "FLOAT",
+		"NODATA": "-9999", "ULXMAP": "10", "ULYMAP": "23", "XDIM": "2", "YDIM": "3",
+	}
+	for key, value := range expected {
+		if header[key] != value {
+			t.Errorf("%s is %q, expected %q", key, header[key], value)
+		}
+	}
+	if len(rest) != 0 {
+		t.Errorf("the header ends with %q", rest)
+	}
+
+	// NBITS x NROWS x NCOLS of little-endian (I for Intel) floats, the upper left first
+	if r32.Len() != 32/8*2*3 {
+		t.Fatalf("the r32 is %d bytes for a 3x2 field", r32.Len())
+	}
+	heights := make([]float32, 6)
+	binary.Read(&r32, binary.LittleEndian, heights)
+	for i, h := range []float32{3, 4, 5, 0, 1, 2} {
+		if heights[i] != h {
+			t.Fatalf("the r32 heights are %v, expected the top row 3 4 5 first", heights)
+		}
+	}
+}
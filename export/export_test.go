@@ -0,0 +1,12 @@
+package export
+
+import "terrain-generation/terrain"
+
+/*
+ * Generates the height field the tests of the exporters write
+ * @param width The number of vertices in the x direction
+ * @param height The number of vertices in the y direction
+ */
+func testField(width, height uint32) *terrain.HeightField {
+	return terrain.NewSimpleTerrain(terrain.NewGradientBoard(5, 5, 43), terrain.DomainWarp{}, width, height, 1.5).HeightField()
+}
@@ -0,0 +1,249 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"terrain-generation/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//==========================================GeoTIFF===========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The tiff and geotiff tags written by the writer
+const (
+	tiffImageWidth      = 256
+	tiffImageLength     = 257
+	tiffBitsPerSample   = 258
+	tiffCompression     = 259
+	tiffPhotometric     = 262
+	tiffStripOffsets    = 273
+	tiffSamplesPerPixel = 277
+	tiffRowsPerStrip    = 278
+	tiffStripByteCounts = 279
+	tiffPlanarConfig    = 284
+	tiffSampleFormat    = 339
+	tiffModelPixelScale = 33550
+	tiffModelTiepoint   = 33922
+	tiffGeoKeyDirectory = 34735
+)
+
+// The tiff field types used by the writer
+const (
+	tiffShort  = 3
+	tiffLong   = 4
+	tiffDouble = 12
+)
+
+// The geo keys written by the writer
+const (
+	geoModelType          = 1024
+	geoRasterType         = 1025
+	geoGeographicType     = 2048
+	geoProjectedCSType    = 3072
+	geoModelProjected     = 1
+	geoModelGeographic    = 2
+	geoRasterPixelIsArea  = 1
+	tiffSampleFormatFloat = 3
+)
+
+// The EPSG codes of common geographic 2D coordinate reference systems, which are written with the geographic model type
+var geographicCRS = map[uint16]bool{
+	4004: true, // Unknown datum based upon the Bessel 1841 ellipsoid
+	4019: true, // Unknown datum based upon the GRS 1980 ellipsoid
+	4148: true, // Hartebeesthoek94
+	4167: true, // NZGD2000
+	4202: true, // AGD66
+	4203: true, // AGD84
+	4230: true, // ED50
+	4258: true, // ETRS89
+	4267: true, // NAD27
+	4269: true, // NAD83
+	4275: true, // NTF
+	4277: true, // OSGB36
+	4283: true, // GDA94
+	4301: true, // Tokyo
+	4312: true, // MGI
+	4314: true, // DHDN
+	4322: true, // WGS 72
+	4326: true, // WGS 84
+	4490: true, // CGCS2000
+	4610: true, // Xian 1980
+	4612: true, // JGD2000
+	4617: true, // NAD83(CSRS)
+	4674: true, // SIRGAS 2000
+	4686: true, // MAGNA-SIRGAS
+	4759: true, // NAD83(NSRS2007)
+	6318: true, // NAD83(2011)
+	7844: true, // GDA2020
+}
+
+// The EPSG codes of projected coordinate reference systems among the geographic codes from 4000 to 4999
+var projectedGeographicBlockCRS = map[uint16]bool{
+	4026: true, // MOLDREF99 / Moldova TM
+	4037: true, // WGS 84 / TMzn35N
+	4038: true, // WGS 84 / TMzn36N
+	4087: true, // WGS 84 / World Equidistant Cylindrical
+	4088: true, // World Equidistant Cylindrical (Sphere)
+	4647: true, // ETRS89 / UTM zone 32N (zE-N)
+	4839: true, // ETRS89 / LCC Germany (N-E)
+}
+
+/*
+ * Finds the geotiff model type of a coordinate reference system from its EPSG code. Geographic codes are looked up in a
+ * table rather than guessed from their range, since the codes from 4000 to 4999 also hold projected, geocentric and 3D
+ * systems. Codes in that range that are in neither table are rejected, and every other code is projected.
+ * @param crs The EPSG code of the coordinate reference system
+ */
+func crsModelType(crs uint16) (uint16, error) {
+	switch {
+	case geographicCRS[crs]:
+		return geoModelGeographic, nil
+	case projectedGeographicBlockCRS[crs]:
+		return geoModelProjected, nil
+	case crs >= 4000 && crs < 5000:
+		return 0, fmt.Errorf("EPSG %d is not a geographic or projected 2D coordinate reference system known to the geotiff writer", crs)
+	}
+	return geoModelProjected, nil
+}
+
+// A field of an image file directory, its value is a slice of uint16, uint32 or float64
+type tiffField struct {
+	tag   uint16
+	value interface{}
+}
+
+/*
+ * Writes a height field as a single band float32 geotiff, north row first. The cells are centered on the vertices of
+ * the field and placed with the ModelTiepoint and ModelPixelScale tags the same way WriteASC places them, and the CRS
+ * of the options is written as an EPSG code in the geo key directory.
+ * @param w The writer the geotiff is written to
+ * @param field The height field to write
+ * @param options The cell size, origin and CRS of the grid
+ */
+func WriteGeoTIFF(w io.Writer, field *terrain.HeightField, options DEMOptions) error {
+	cellX, cellY, x, y := options.georeference(field)
+	width, height := field.Width(), field.Height()
+
+	// The tiepoint places the upper left corner of the upper left cell, half a cell from its center
+	left := x - cellX/2
+	top := y + (float64(height)-0.5)*cellY
+	geoKeys := []uint16{1, 1, 0, 1, geoRasterType, 0, 1, geoRasterPixelIsArea}
+	if options.CRS != 0 {
+		modelType, err := crsModelType(options.CRS)
+		if err != nil {
+			return err
+		}
+		if modelType == geoModelGeographic {
+			geoKeys = append(geoKeys, geoModelType, 0, 1, geoModelGeographic, geoGeographicType, 0, 1, options.CRS)
+		} else {
+			geoKeys = append(geoKeys, geoModelType, 0, 1, geoModelProjected, geoProjectedCSType, 0, 1, options.CRS)
+		}
+		// The keys of the directory are sorted by key id after its header
+		keys := geoKeys[4:]
+		sort.Sort(geoKeyEntries(keys))
+		geoKeys[3] = uint16(len(keys) / 4)
+	}
+
+	imageSize := width * height * 4
+	fields := []tiffField{
+		{tiffImageWidth, []uint32{width}},
+		{tiffImageLength, []uint32{height}},
+		{tiffBitsPerSample, []uint16{32}},
+		{tiffCompression, []uint16{1}},
+		{tiffPhotometric, []uint16{1}},
+		{tiffStripOffsets, []uint32{0}},
+		{tiffSamplesPerPixel, []uint16{1}},
+		{tiffRowsPerStrip, []uint32{height}},
+		{tiffStripByteCounts, []uint32{imageSize}},
+		{tiffPlanarConfig, []uint16{1}},
+		{tiffSampleFormat, []uint16{tiffSampleFormatFloat}},
+		{tiffModelPixelScale, []float64{cellX, cellY, 0}},
+		{tiffModelTiepoint, []float64{0, 0, 0, left, top, 0}},
+		{tiffGeoKeyDirectory, geoKeys},
+	}
+
+	// The header is followed by the image file directory, then the values that do not fit in its entries, then the image
+	directorySize := 2 + 12*len(fields) + 4
+	var extra bytes.Buffer
+	offset := 8 + directorySize
+	entries := make([][]byte, len(fields))
+	for i, f := range fields {
+		var value bytes.Buffer
+		binary.Write(&value, binary.LittleEndian, f.value)
+		typ, count := tiffFieldType(f.value)
+		entry := make([]byte, 12)
+		binary.LittleEndian.PutUint16(entry[0:], f.tag)
+		binary.LittleEndian.PutUint16(entry[2:], typ)
+		binary.LittleEndian.PutUint32(entry[4:], count)
+		if value.Len() <= 4 {
+			copy(entry[8:], value.Bytes())
+		} else {
+			binary.LittleEndian.PutUint32(entry[8:], uint32(offset+extra.Len()))
+			extra.Write(value.Bytes())
+			// Values are aligned to an even offset
+			if extra.Len()%2 != 0 {
+				extra.WriteByte(0)
+			}
+		}
+		entries[i] = entry
+	}
+	imageOffset := uint32(offset + extra.Len())
+	for i, f := range fields {
+		if f.tag == tiffStripOffsets {
+			binary.LittleEndian.PutUint32(entries[i][8:], imageOffset)
+		}
+	}
+
+	var out bytes.Buffer
+	out.Write([]byte{'I', 'I', 42, 0})
+	binary.Write(&out, binary.LittleEndian, uint32(8))
+	binary.Write(&out, binary.LittleEndian, uint16(len(fields)))
+	for _, entry := range entries {
+		out.Write(entry)
+	}
+	binary.Write(&out, binary.LittleEndian, uint32(0))
+	out.Write(extra.Bytes())
+	for row := int(height) - 1; row >= 0; row-- {
+		start := row * int(width)
+		binary.Write(&out, binary.LittleEndian, field.Heights()[start:start+int(width)])
+	}
+	_, err := out.WriteTo(w)
+	return err
+}
+
+/*
+ * The tiff field type and number of values of the value of a field
+ * @param value A slice of uint16, uint32 or float64
+ */
+func tiffFieldType(value interface{}) (uint16, uint32) {
+	switch v := value.(type) {
+	case []uint16:
+		return tiffShort, uint32(len(v))
+	case []uint32:
+		return tiffLong, uint32(len(v))
+	case []float64:
+		return tiffDouble, uint32(len(v))
+	}
+	return 0, 0
+}
+
+// The entries of a geo key directory, four shorts each, sorted by their key id
+type geoKeyEntries []uint16
+
+func (keys geoKeyEntries) Len() int {
+	return len(keys) / 4
+}
+
+func (keys geoKeyEntries) Less(i, j int) bool {
+	return keys[i*4] < keys[j*4]
+}
+
+func (keys geoKeyEntries) Swap(i, j int) {
+	for k := 0; k < 4; k++ {
+		keys[i*4+k], keys[j*4+k] = keys[j*4+k], keys[i*4+k]
+	}
+}
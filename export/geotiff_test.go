@@ -0,0 +1,147 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+/*
+ * Reads the fields of the first image file directory of a little-endian tiff, by tag
+ * @param t The test reading the tiff
+ * @param data The tiff
+ */
+func readTIFFDirectory(t *testing.T, data []byte) map[uint16][]float64 {
+	if string(data[:4]) != "II*\x00" {
+		t.Fatalf("the tiff starts with %q, not a little-endian header", data[:4])
+	}
+	le := binary.LittleEndian
+	offset := le.Uint32(data[4:])
+	count := int(le.Uint16(data[offset:]))
+	fields := map[uint16][]float64{}
+	for i := 0; i < count; i++ {
+		entry := data[int(offset)+2+i*12:]
+		tag, typ, n := le.Uint16(entry), le.Uint16(entry[2:]), int(le.Uint32(entry[4:]))
+		size := map[uint16]int{tiffShort: 2, tiffLong: 4, tiffDouble: 8}[typ]
+		if size == 0 {
+			t.Fatalf("tag %d has an unknown field type %d", tag, typ)
+		}
+		value := entry[8:12]
+		if size*n > 4 {
+			value = data[le.Uint32(entry[8:]):]
+		}
+		for k := 0; k < n; k++ {
+			switch typ {
+			case tiffShort:
+				fields[tag] = append(fields[tag], float64(le.Uint16(value[k*2:])))
+			case tiffLong:
+				fields[tag] = append(fields[tag], float64(le.Uint32(value[k*4:])))
+			case tiffDouble:
+				fields[tag] = append(fields[tag], math.Float64frombits(le.Uint64(value[k*8:])))
+			}
+		}
+	}
+	return fields
+}
+
+// The image file directory describes a single float32 strip placed by its tiepoint, scale and the EPSG code of its CRS
+func TestWriteGeoTIFFTags(t *testing.T) {
+	field := testField(9, 5)
+	for _, crs := range []uint16{0, 4326, 7844, 32633, 4087} {
+		options := DEMOptions{CellSize: 30, OriginX: 500000, OriginY: 4200000, CRS: crs}
+		var buf bytes.Buffer
+		if err := WriteGeoTIFF(&buf, field, options); err != nil {
+			t.Fatal(err)
+		}
+		data := buf.Bytes()
+		fields := readTIFFDirectory(t, data)
+
+		expect := map[uint16][]float64{
+			tiffImageWidth:      {9},
+			tiffImageLength:     {5},
+			tiffBitsPerSample:   {32},
+			tiffCompression:     {1},
+			tiffSamplesPerPixel: {1},
+			tiffRowsPerStrip:    {5},
+			tiffStripByteCounts: {9 * 5 * 4},
+			tiffSampleFormat:    {tiffSampleFormatFloat},
+			tiffModelPixelScale: {30, 30, 0},
+		}
+		for tag, values := range expect {
+			if !equalValues(fields[tag], values) {
+				t.Errorf("crs %d: tag %d is %v, expected %v", crs, tag, fields[tag], values)
+			}
+		}
+
+		// The tiepoint is the upper left corner of the upper left cell
+		cellX, cellY, x, y := options.georeference(field)
+		tiepoint := []float64{0, 0, 0, x - cellX/2, y + 4.5*cellY, 0}
+		if !equalValues(fields[tiffModelTiepoint], tiepoint) {
+			t.Errorf("crs %d: tiepoint is %v, expected %v", crs, fields[tiffModelTiepoint], tiepoint)
+		}
+
+		keys := map[float64]float64{}
+		directory := fields[tiffGeoKeyDirectory]
+		if len(directory) < 4 || int(directory[3])*4+4 != len(directory) {
+			t.Fatalf("crs %d: the geo key directory %v does not hold the number of keys in its header", crs, directory)
+		}
+		for k := 4; k < len(directory); k += 4 {
+			if k > 4 && directory[k] <= directory[k-4] {
+				t.Errorf("crs %d: geo key %v is not sorted after %v", crs, directory[k], directory[k-4])
+			}
+			keys[directory[k]] = directory[k+3]
+		}
+		if keys[geoRasterType] != geoRasterPixelIsArea {
+			t.Errorf("crs %d: raster type is %v", crs, keys[geoRasterType])
+		}
+		switch crs {
+		case 4326, 7844:
+			if keys[geoModelType] != geoModelGeographic || keys[geoGeographicType] != float64(crs) {
+				t.Errorf("crs %d: geo keys %v are not geographic", crs, keys)
+			}
+		case 32633, 4087:
+			if keys[geoModelType] != geoModelProjected || keys[geoProjectedCSType] != float64(crs) {
+				t.Errorf("crs %d: geo keys %v are not projected", crs, keys)
+			}
+		}
+
+		// The strip starts with the highest row of the field
+		strip := data[int(fields[tiffStripOffsets][0]):]
+		if len(strip) != 9*5*4 {
+			t.Fatalf("crs %d: the strip is %d bytes", crs, len(strip))
+		}
+		for col := 0; col < 9; col++ {
+			if h := math.Float32frombits(binary.LittleEndian.Uint32(strip[col*4:])); h != field.At(col, 4) {
+				t.Errorf("crs %d: the first row has %v at column %d, expected %v", crs, h, col, field.At(col, 4))
+			}
+		}
+	}
+}
+
+// Whether two lists of values are the same, to within the rounding of the georeferencing
+func equalValues(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9*math.Max(1, math.Abs(b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// Codes among the geographic codes from 4000 to 4999 that are not known to be geographic or projected, like the
+// geocentric 4978, are rejected rather than written with a model type that may be wrong
+func TestWriteGeoTIFFUnknownCRS(t *testing.T) {
+	for _, crs := range []uint16{4978, 4979, 4999} {
+		var buf bytes.Buffer
+		if err := WriteGeoTIFF(&buf, testField(9, 5), DEMOptions{CellSize: 30, CRS: crs}); err == nil {
+			t.Errorf("a geotiff with the crs %d was written", crs)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("crs %d: %d bytes were written for a rejected geotiff", crs, buf.Len())
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"terrain-generation/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//============================================GLB=============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The options of a binary gltf 2.0 (glb) export
+type GLBOptions struct {
+	// Whether every vertex is given a color from its height, from water blue through sand, grass and rock to snow white
+	VertexColors bool
+	// The number of vertices along each side of a chunk. The height field is split into chunks that each become a node
+	// with its own mesh, and neighbouring chunks share the vertices along their edges. 0 writes the whole field as one node.
+	ChunkSize uint32
+}
+
+// The gltf constants used by the writer
+const (
+	glbMagic          = 0x46546C67
+	glbChunkJSON      = 0x4E4F534A
+	glbChunkBIN       = 0x004E4942
+	gltfFloat         = 5126
+	gltfUnsignedInt   = 5125
+	gltfArrayBuffer   = 34962
+	gltfElementBuffer = 34963
+	gltfTriangles     = 4
+)
+
+// The parts of a gltf document written by the exporter
+type gltfDocument struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes"`
+	Meshes      []gltfMesh       `json:"meshes"`
+	Materials   []gltfMaterial   `json:"materials"`
+	Accessors   []gltfAccessor   `json:"accessors"`
+	BufferViews []gltfBufferView `json:"bufferViews"`
+	Buffers     []gltfBuffer     `json:"buffers"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name string `json:"name"`
+	Mesh int    `json:"mesh"`
+}
+
+type gltfMesh struct {
+	Name       string          `json:"name"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    int            `json:"indices"`
+	Material   int            `json:"material"`
+	Mode       int            `json:"mode"`
+}
+
+type gltfMaterial struct {
+	Name                 string          `json:"name"`
+	PBRMetallicRoughness gltfPBRMaterial `json:"pbrMetallicRoughness"`
+}
+
+type gltfPBRMaterial struct {
+	BaseColorFactor [4]float32 `json:"baseColorFactor"`
+	MetallicFactor  float32    `json:"metallicFactor"`
+	RoughnessFactor float32    `json:"roughnessFactor"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target"`
+}
+
+type gltfBuffer struct {
+	ByteLength int `json:"byteLength"`
+}
+
+/*
+ * Writes the mesh of a height field as a binary gltf 2.0 file. Gltf is y up, so the terrain's z up world coordinates are
+ * written as (x, z, -y). Every chunk of the field becomes a node of the scene with a mesh of positions, normals, texture
+ * coordinates and, optionally, vertex colors.
+ * @param w The writer the glb is written to
+ * @param field The height field to write
+ * @param options The vertex colors and chunks of the glb
+ */
+func WriteGLB(w io.Writer, field *terrain.HeightField, options GLBOptions) error {
+	doc := gltfDocument{
+		Asset:     gltfAsset{Version: "2.0", Generator: "terrain-generation"},
+		Scenes:    []gltfScene{{}},
+		Materials: []gltfMaterial{{Name: materialName, PBRMetallicRoughness: gltfPBRMaterial{BaseColorFactor: [4]float32{0.66, 0.66, 0.66, 1}, RoughnessFactor: 1}}},
+	}
+	if options.VertexColors {
+		// The vertex colors are multiplied by the base color, so it is white when they are used
+		doc.Materials[0].PBRMetallicRoughness.BaseColorFactor = [4]float32{1, 1, 1, 1}
+	}
+	var bin bytes.Buffer
+	low, high := field.Range()
+
+	chunkSize := options.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = maxUint32(field.Width(), field.Height())
+	}
+	// A chunk needs at least one cell, so that the next chunk starts after it
+	chunkSize = maxUint32(chunkSize, 2)
+	for row := 0; row+1 < int(field.Height()) || row == 0; row += int(chunkSize) - 1 {
+		for col := 0; col+1 < int(field.Width()) || col == 0; col += int(chunkSize) - 1 {
+			width := minUint32(chunkSize, field.Width()-uint32(col))
+			height := minUint32(chunkSize, field.Height()-uint32(row))
+			mesh := terrain.NewRegionMesh(field, col, row, width, height)
+
+			primitive := gltfPrimitive{Attributes: map[string]int{}, Material: 0, Mode: gltfTriangles}
+			primitive.Attributes["POSITION"] = doc.addVec3(&bin, yUp(mesh.Positions), true)
+			primitive.Attributes["NORMAL"] = doc.addVec3(&bin, yUp(mesh.Normals), false)
+			primitive.Attributes["TEXCOORD_0"] = doc.addAccessor(&bin, mesh.UVs, "VEC2", gltfFloat, gltfArrayBuffer, nil, nil)
+			if options.VertexColors {
+				colors := make([]float32, 0, mesh.VertexCount()*3)
+				for i := 0; i < mesh.VertexCount(); i++ {
+					colors = append(colors, heightColor(mesh.Positions[i*3+2], low, high)...)
+				}
+				primitive.Attributes["COLOR_0"] = doc.addVec3(&bin, colors, false)
+			}
+			primitive.Indices = doc.addAccessor(&bin, mesh.Indices, "SCALAR", gltfUnsignedInt, gltfElementBuffer, nil, nil)
+
+			name := fmt.Sprintf("terrain_%d_%d", col, row)
+			doc.Meshes = append(doc.Meshes, gltfMesh{Name: name, Primitives: []gltfPrimitive{primitive}})
+			doc.Nodes = append(doc.Nodes, gltfNode{Name: name, Mesh: len(doc.Meshes) - 1})
+			doc.Scenes[0].Nodes = append(doc.Scenes[0].Nodes, len(doc.Nodes)-1)
+		}
+	}
+	doc.Buffers = []gltfBuffer{{ByteLength: bin.Len()}}
+
+	jsonChunk, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	// Both chunks are padded to a multiple of 4 bytes, the json with spaces and the binary data with zeros
+	for len(jsonChunk)%4 != 0 {
+		jsonChunk = append(jsonChunk, ' ')
+	}
+	for bin.Len()%4 != 0 {
+		bin.WriteByte(0)
+	}
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, []uint32{glbMagic, 2, uint32(12 + 8 + len(jsonChunk) + 8 + bin.Len())})
+	binary.Write(&out, binary.LittleEndian, []uint32{uint32(len(jsonChunk)), glbChunkJSON})
+	out.Write(jsonChunk)
+	binary.Write(&out, binary.LittleEndian, []uint32{uint32(bin.Len()), glbChunkBIN})
+	out.Write(bin.Bytes())
+	_, err = out.WriteTo(w)
+	return err
+}
+
+/*
+ * Adds a float vec3 accessor to the document, with the bounds gltf requires for positions
+ * @param bin The binary chunk the data is appended to
+ * @param data The x, y and z components of every element
+ * @param bounds Whether the minimum and maximum of each component are written
+ */
+func (doc *gltfDocument) addVec3(bin *bytes.Buffer, data []float32, bounds bool) int {
+	if !bounds || len(data) == 0 {
+		return doc.addAccessor(bin, data, "VEC3", gltfFloat, gltfArrayBuffer, nil, nil)
+	}
+	low := []float32{data[0], data[1], data[2]}
+	high := []float32{data[0], data[1], data[2]}
+	for i := 3; i < len(data); i++ {
+		low[i%3] = float32(math.Min(float64(low[i%3]), float64(data[i])))
+		high[i%3] = float32(math.Max(float64(high[i%3]), float64(data[i])))
+	}
+	return doc.addAccessor(bin, data, "VEC3", gltfFloat, gltfArrayBuffer, low, high)
+}
+
+/*
+ * Appends the data of an accessor to the binary chunk and adds the accessor and its buffer view to the document
+ * @param bin The binary chunk the data is appended to
+ * @param data A slice of float32 or uint32 components
+ * @param typ The gltf type of an element: SCALAR, VEC2 or VEC3
+ * @param componentType The gltf type of a component
+ * @param target The buffer the data is bound to
+ * @param low The minimum of each component, or nil
+ * @param high The maximum of each component, or nil
+ */
+func (doc *gltfDocument) addAccessor(bin *bytes.Buffer, data interface{}, typ string, componentType, target int, low, high []float32) int {
+	offset := bin.Len()
+	binary.Write(bin, binary.LittleEndian, data)
+	components := map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3}[typ]
+	doc.BufferViews = append(doc.BufferViews, gltfBufferView{Buffer: 0, ByteOffset: offset, ByteLength: bin.Len() - offset, Target: target})
+	doc.Accessors = append(doc.Accessors, gltfAccessor{
+		BufferView:    len(doc.BufferViews) - 1,
+		ComponentType: componentType,
+		Count:         (bin.Len() - offset) / 4 / components,
+		Type:          typ,
+		Min:           low,
+		Max:           high,
+	})
+	return len(doc.Accessors) - 1
+}
+
+/*
+ * Converts z up vectors into y up vectors by writing (x, y, z) as (x, z, -y)
+ * @param data The x, y and z components of every vector
+ */
+func yUp(data []float32) []float32 {
+	converted := make([]float32, len(data))
+	for i := 0; i+2 < len(data); i += 3 {
+		converted[i], converted[i+1], converted[i+2] = data[i], data[i+2], -data[i+1]
+	}
+	return converted
+}
+
+// The colors of the height ramp of vertex colors, from the lowest to the highest height
+var heightRamp = []struct {
+	t     float32
+	color [3]float32
+}{
+	{0, [3]float32{0.10, 0.25, 0.55}},
+	{0.3, [3]float32{0.80, 0.75, 0.50}},
+	{0.4, [3]float32{0.30, 0.55, 0.20}},
+	{0.7, [3]float32{0.45, 0.38, 0.30}},
+	{1, [3]float32{0.95, 0.95, 0.95}},
+}
+
+/*
+ * Colors a height by interpolating the height ramp
+ * @param h The height to color
+ * @param low The lowest height of the field
+ * @param high The highest height of the field
+ */
+func heightColor(h, low, high float32) []float32 {
+	t := float32(0)
+	if high > low {
+		t = (h - low) / (high - low)
+	}
+	for i := 1; i < len(heightRamp); i++ {
+		if t <= heightRamp[i].t || i == len(heightRamp)-1 {
+			a, b := heightRamp[i-1], heightRamp[i]
+			s := (t - a.t) / (b.t - a.t)
+			if s < 0 {
+				s = 0
+			} else if s > 1 {
+				s = 1
+			}
+			return []float32{
+				a.color[0] + (b.color[0]-a.color[0])*s,
+				a.color[1] + (b.color[1]-a.color[1])*s,
+				a.color[2] + (b.color[2]-a.color[2])*s,
+			}
+		}
+	}
+	return heightRamp[0].color[:]
+}
+
+// The larger of two uint32
+func maxUint32(a, b uint32) uint32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// The smaller of two uint32
+func minUint32(a, b uint32) uint32 {
+	if a < b {
+		return a
+	}
+	return b
+}
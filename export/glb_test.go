@@ -0,0 +1,156 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+)
+
+/*
+ * Splits a glb into its json document and binary chunk, checking the header and the chunk lengths
+ * @param t The test
+ * @param data The bytes of the glb
+ */
+func readGLB(t *testing.T, data []byte) (gltfDocument, []byte) {
+	if len(data) < 20 {
+		t.Fatalf("the glb is only %d bytes", len(data))
+	}
+	header := make([]uint32, 3)
+	binary.Read(bytes.NewReader(data), binary.LittleEndian, header)
+	if header[0] != glbMagic || header[1] != 2 || int(header[2]) != len(data) {
+		t.Fatalf("the header is %x, version %d, length %d of %d bytes", header[0], header[1], header[2], len(data))
+	}
+
+	var chunks [][]byte
+	for offset := 12; offset < len(data); {
+		length := int(binary.LittleEndian.Uint32(data[offset:]))
+		typ := binary.LittleEndian.Uint32(data[offset+4:])
+		if length%4 != 0 || offset+8+length > len(data) {
+			t.Fatalf("chunk %d of %d bytes at %d does not fit a %d byte glb", len(chunks), length, offset, len(data))
+		}
+		if expected := []uint32{glbChunkJSON, glbChunkBIN}[len(chunks)]; typ != expected {
+			t.Fatalf("chunk %d has the type %x, expected %x", len(chunks), typ, expected)
+		}
+		chunks = append(chunks, data[offset+8:offset+8+length])
+		offset += 8 + length
+		if len(chunks) == 2 && offset != len(data) {
+			t.Fatalf("%d bytes follow the binary chunk", len(data)-offset)
+		}
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("the glb has %d chunks, expected a json and a binary chunk", len(chunks))
+	}
+
+	var doc gltfDocument
+	if err := json.Unmarshal(chunks[0], &doc); err != nil {
+		t.Fatalf("the json chunk can not be read: %v", err)
+	}
+	if len(doc.Buffers) != 1 || doc.Buffers[0].ByteLength > len(chunks[1]) {
+		t.Fatalf("the buffers %+v do not fit the %d byte binary chunk", doc.Buffers, len(chunks[1]))
+	}
+	return doc, chunks[1]
+}
+
+/*
+ * Reads the uint32 or float32 components of an accessor from the binary chunk
+ * @param t The test
+ * @param doc The document of the accessor
+ * @param bin The binary chunk
+ * @param accessor The index of the accessor
+ * @param components A slice of the components, sized by the accessor
+ */
+func readAccessor(t *testing.T, doc gltfDocument, bin []byte, accessor int, components interface{}) {
+	view := doc.BufferViews[doc.Accessors[accessor].BufferView]
+	if view.ByteOffset+view.ByteLength > len(bin) {
+		t.Fatalf("the buffer view %+v does not fit the %d byte binary chunk", view, len(bin))
+	}
+	if err := binary.Read(bytes.NewReader(bin[view.ByteOffset:view.ByteOffset+view.ByteLength]), binary.LittleEndian, components); err != nil {
+		t.Fatalf("accessor %d can not be read: %v", accessor, err)
+	}
+}
+
+// A glb holds a mesh for every chunk, with bounded positions, indices of its own vertices and colors when they are enabled
+func TestWriteGLB(t *testing.T) {
+	field := testField(9, 7)
+	low, high := field.Range()
+	extent := field.Extent()
+	tests := []struct {
+		options GLBOptions
+		nodes   int
+	}{
+		{GLBOptions{}, 1},
+		{GLBOptions{VertexColors: true}, 1},
+		// Chunks of 5 vertices cover 4 cells, so 8 x 6 cells are 2 x 2 chunks
+		{GLBOptions{ChunkSize: 5}, 4},
+		{GLBOptions{ChunkSize: 3, VertexColors: true}, 12},
+		{GLBOptions{ChunkSize: 100}, 1},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := WriteGLB(&buf, field, test.options); err != nil {
+			t.Fatal(err)
+		}
+		doc, bin := readGLB(t, buf.Bytes())
+		if len(doc.Nodes) != test.nodes || len(doc.Meshes) != test.nodes || len(doc.Scenes[0].Nodes) != test.nodes {
+			t.Fatalf("%+v: %d nodes, %d meshes and %d scene nodes, expected %d", test.options, len(doc.Nodes), len(doc.Meshes), len(doc.Scenes[0].Nodes), test.nodes)
+		}
+
+		vertices, triangles := 0, 0
+		for _, mesh := range doc.Meshes {
+			primitive := mesh.Primitives[0]
+			if _, ok := primitive.Attributes["COLOR_0"]; ok != test.options.VertexColors {
+				t.Errorf("%+v: %s has COLOR_0 %v", test.options, mesh.Name, ok)
+			}
+			positions := doc.Accessors[primitive.Attributes["POSITION"]]
+			for _, attribute := range []string{"NORMAL", "TEXCOORD_0", "COLOR_0"} {
+				if accessor, ok := primitive.Attributes[attribute]; ok && doc.Accessors[accessor].Count != positions.Count {
+					t.Errorf("%+v: %s has %d %s for %d positions", test.options, mesh.Name, doc.Accessors[accessor].Count, attribute, positions.Count)
+				}
+			}
+
+			data := make([]float32, positions.Count*3)
+			readAccessor(t, doc, bin, primitive.Attributes["POSITION"], data)
+			min := []float32{data[0], data[1], data[2]}
+			max := []float32{data[0], data[1], data[2]}
+			for i, v := range data {
+				if v < min[i%3] {
+					min[i%3] = v
+				}
+				if v > max[i%3] {
+					max[i%3] = v
+				}
+			}
+			for k := 0; k < 3; k++ {
+				if positions.Min[k] != min[k] || positions.Max[k] != max[k] {
+					t.Errorf("%+v: %s has the bounds %v to %v, the positions span %v to %v", test.options, mesh.Name, positions.Min, positions.Max, min, max)
+				}
+			}
+			// The positions are y up, so the heights are the y components and the y of the field is the negated z
+			if min[0] < float32(extent.MinX) || max[0] > float32(extent.MaxX) || min[1] < low || max[1] > high || -max[2] < float32(extent.MinY) || -min[2] > float32(extent.MaxY) {
+				t.Errorf("%+v: %s spans %v to %v outside of the field", test.options, mesh.Name, min, max)
+			}
+
+			indices := doc.Accessors[primitive.Indices]
+			if indices.Count%3 != 0 {
+				t.Errorf("%+v: %s has %d indices", test.options, mesh.Name, indices.Count)
+			}
+			data32 := make([]uint32, indices.Count)
+			readAccessor(t, doc, bin, primitive.Indices, data32)
+			for _, index := range data32 {
+				if int(index) >= positions.Count {
+					t.Fatalf("%+v: %s has the index %d of %d vertices", test.options, mesh.Name, index, positions.Count)
+				}
+			}
+			vertices += positions.Count
+			triangles += indices.Count / 3
+		}
+		// Neighbouring chunks share the vertices along their edges, and together cover every cell once
+		if triangles != 2*8*6 {
+			t.Errorf("%+v: %d triangles, expected %d", test.options, triangles, 2*8*6)
+		}
+		if vertices < 9*7 {
+			t.Errorf("%+v: %d vertices, expected at least %d", test.options, vertices, 9*7)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Package export writes the height fields of generated terrains to files that other programs can read. Every writer
+// takes an io.Writer, so it works the same for files, network connections and buffers.
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"terrain-generation/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//============================================JSON============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The json object of a height field, the keys are written the same way as the keys of the map files
+type jsonHeightField struct {
+	Width   uint32    `json:"width"`
+	Height  uint32    `json:"height"`
+	MinX    float64   `json:"min_x"`
+	MinY    float64   `json:"min_y"`
+	MaxX    float64   `json:"max_x"`
+	MaxY    float64   `json:"max_y"`
+	Heights []float32 `json:"heights"`
+}
+
+/*
+ * Writes a height field as a json object with its dimensions, its world extent and its heights in row major order
+ * @param w The writer the json is written to
+ * @param field The height field to write
+ */
+func WriteJSON(w io.Writer, field *terrain.HeightField) error {
+	extent := field.Extent()
+	return json.NewEncoder(w).Encode(jsonHeightField{
+		Width:   field.Width(),
+		Height:  field.Height(),
+		MinX:    extent.MinX,
+		MinY:    extent.MinY,
+		MaxX:    extent.MaxX,
+		MaxY:    extent.MaxY,
+		Heights: field.Heights(),
+	})
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"terrain-generation/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//============================================OBJ=============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The name of the material of exported meshes
+const materialName = "terrain"
+
+/*
+ * Writes the mesh of a height field as a wavefront obj with positions, normals, texture coordinates and triangles.
+ * Obj files are y up, so the terrain's z up world coordinates are written as (x, z, -y), which is how Blender and most
+ * other tools import them. The extent of the field is written in a comment so the window of a moved terrain is known.
+ * @param w The writer the obj is written to
+ * @param field The height field to write
+ * @param materialLib The file name of the mtl written by WriteMTL, or an empty name to write the obj without a material
+ */
+func WriteOBJ(w io.Writer, field *terrain.HeightField, materialLib string) error {
+	mesh := terrain.NewMesh(field)
+	extent := field.Extent()
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "# terrain-generation height field of %d x %d vertices\n", field.Width(), field.Height())
+	fmt.Fprintf(out, "# extent x %g to %g, y %g to %g\n", extent.MinX, extent.MaxX, extent.MinY, extent.MaxY)
+	if materialLib != "" {
+		fmt.Fprintf(out, "mtllib %s\n", materialLib)
+	}
+	fmt.Fprintln(out, "o terrain")
+	for i := 0; i < mesh.VertexCount(); i++ {
+		p := mesh.Positions[i*3 : i*3+3]
+		fmt.Fprintf(out, "v %g %g %g\n", p[0], p[2], -p[1])
+	}
+	for i := 0; i < mesh.VertexCount(); i++ {
+		uv := mesh.UVs[i*2 : i*2+2]
+		fmt.Fprintf(out, "vt %g %g\n", uv[0], uv[1])
+	}
+	for i := 0; i < mesh.VertexCount(); i++ {
+		n := mesh.Normals[i*3 : i*3+3]
+		fmt.Fprintf(out, "vn %g %g %g\n", n[0], n[2], -n[1])
+	}
+	if materialLib != "" {
+		fmt.Fprintf(out, "usemtl %s\n", materialName)
+	}
+	fmt.Fprintln(out, "s 1")
+	for i := 0; i < mesh.TriangleCount(); i++ {
+		// Obj indices start at 1, and every vertex uses the position, texture coordinate and normal of the same index
+		a, b, c := mesh.Indices[i*3]+1, mesh.Indices[i*3+1]+1, mesh.Indices[i*3+2]+1
+		fmt.Fprintf(out, "f %d/%d/%d %d/%d/%d %d/%d/%d\n", a, a, a, b, b, b, c, c, c)
+	}
+	return out.Flush()
+}
+
+/*
+ * Writes the material library of an obj written by WriteOBJ, a single dark grey material like the one of the viewer
+ * @param w The writer the mtl is written to
+ */
+func WriteMTL(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "newmtl %s\nKa 0 0 0\nKd 0.66 0.66 0.66\nKs 0 0 0\nd 1\nillum 1\n", materialName)
+	return err
+}
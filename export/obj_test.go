@@ -0,0 +1,81 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"terrain-generation/terrain"
+)
+
+// An obj has a position, texture coordinate and normal for every vertex and a face of 1-based indices for every triangle
+func TestWriteOBJ(t *testing.T) {
+	board := terrain.NewGradientBoard(5, 5, 43)
+	still := terrain.NewSimpleTerrain(board, terrain.DomainWarp{}, 9, 7, 1.5)
+	moved := terrain.NewSimpleTerrain(board, terrain.DomainWarp{}, 9, 7, 1.5)
+	moved.MoveRight(3)
+	moved.MoveUp(2)
+
+	for name, field := range map[string]*terrain.HeightField{"still": still.HeightField(), "moved": moved.HeightField()} {
+		var buf bytes.Buffer
+		if err := WriteOBJ(&buf, field, "terrain.mtl"); err != nil {
+			t.Fatal(err)
+		}
+		vertices := int(field.Width() * field.Height())
+		extent := field.Extent()
+		// The extent is a little larger for the rounding of the float32 positions
+		const epsilon = 1e-4
+		counts := map[string]int{}
+		low := [2]float64{extent.MaxX, extent.MaxY}
+		high := [2]float64{extent.MinX, extent.MinY}
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 0 {
+				continue
+			}
+			counts[fields[0]]++
+			switch fields[0] {
+			case "v":
+				var x, y, z float64
+				fmt.Sscan(strings.Join(fields[1:], " "), &x, &y, &z)
+				// The positions are y up, so the y of the field is the negated z
+				if x < extent.MinX-epsilon || x > extent.MaxX+epsilon || -z < extent.MinY-epsilon || -z > extent.MaxY+epsilon {
+					t.Errorf("%s: the position %v %v %v is outside of the extent %+v", name, x, y, z, extent)
+				}
+				low = [2]float64{math.Min(low[0], x), math.Min(low[1], -z)}
+				high = [2]float64{math.Max(high[0], x), math.Max(high[1], -z)}
+			case "f":
+				for _, corner := range fields[1:] {
+					var p, uv, n int
+					if _, err := fmt.Sscanf(corner, "%d/%d/%d", &p, &uv, &n); err != nil {
+						t.Fatalf("%s: the face corner %q can not be read: %v", name, corner, err)
+					}
+					if p < 1 || p > vertices || uv != p || n != p {
+						t.Fatalf("%s: the face corner %q is not the 1-based index of one of %d vertices", name, corner, vertices)
+					}
+				}
+			}
+		}
+		triangles := 2 * int(field.Width()-1) * int(field.Height()-1)
+		if counts["v"] != vertices || counts["vt"] != vertices || counts["vn"] != vertices || counts["f"] != triangles {
+			t.Errorf("%s: %d v, %d vt, %d vn and %d f lines, expected %d vertices and %d triangles", name, counts["v"], counts["vt"], counts["vn"], counts["f"], vertices, triangles)
+		}
+		// The positions span the whole window, wherever it has moved to
+		if math.Abs(low[0]-extent.MinX) > epsilon || math.Abs(low[1]-extent.MinY) > epsilon || math.Abs(high[0]-extent.MaxX) > epsilon || math.Abs(high[1]-extent.MaxY) > epsilon {
+			t.Errorf("%s: the positions span %v to %v, expected the extent %+v", name, low, high, extent)
+		}
+		if counts["mtllib"] != 1 || counts["usemtl"] != 1 {
+			t.Errorf("%s: %d mtllib and %d usemtl lines", name, counts["mtllib"], counts["usemtl"])
+		}
+	}
+
+	// The window of the moved terrain is displaced from the one of the terrain that did not move
+	a, b := still.HeightField().Extent(), moved.HeightField().Extent()
+	if b.MinX <= a.MinX || b.MinY <= a.MinY || b.Width() != a.Width() || b.Height() != a.Height() {
+		t.Errorf("the moved terrain covers %+v, the terrain that did not move covers %+v", b, a)
+	}
+}
@@ -0,0 +1,125 @@
+package export
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"terrain-generation/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=======================================Normalization========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The way heights are mapped onto the gray levels of a heightmap image
+type Normalization uint8
+
+const (
+	// The lowest height of the field is black and the highest is white, using every gray level
+	NormalizeMinMax Normalization = iota
+	// A fixed range of heights is mapped from black to white, so heightmaps of different terrains share a scale.
+	// Heights outside of the range are clamped.
+	NormalizeFixed
+)
+
+// The normalizations as they are written on the command line
+var normalizationNames = map[string]Normalization{
+	"minmax": NormalizeMinMax,
+	"fixed":  NormalizeFixed,
+}
+
+/*
+ * Finds the normalization with a name from the command line
+ * @param name The name of the normalization
+ */
+func ParseNormalization(name string) (Normalization, bool) {
+	normalization, ok := normalizationNames[name]
+	return normalization, ok
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//============================================PNG=============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The options of a 16-bit grayscale png heightmap
+type PNGOptions struct {
+	// The way heights are mapped onto the gray levels
+	Normalization Normalization
+	// The heights that are black and white with NormalizeFixed
+	Low, High float64
+	// The size of the image in pixels, a size of 0 uses the number of vertices of the height field in that direction.
+	// A field with a different number of vertices is resampled with bicubic interpolation.
+	Width, Height uint32
+}
+
+/*
+ * Writes a height field as a 16-bit grayscale png heightmap. The top row of the image is the highest y of the field's
+ * extent, so the image is oriented the same way as the terrain seen from above.
+ * @param w The writer the png is written to
+ * @param field The height field to write
+ * @param options The normalization and size of the image
+ */
+func WritePNG(w io.Writer, field *terrain.HeightField, options PNGOptions) error {
+	width, height := options.Width, options.Height
+	if width == 0 {
+		width = field.Width()
+	}
+	if height == 0 {
+		height = field.Height()
+	}
+
+	low, high := options.Low, options.High
+	if options.Normalization == NormalizeMinMax {
+		fieldLow, fieldHigh := field.Range()
+		low, high = float64(fieldLow), float64(fieldHigh)
+	}
+
+	img := image.NewGray16(image.Rect(0, 0, int(width), int(height)))
+	extent := field.Extent()
+	for py := 0; py < int(height); py++ {
+		for px := 0; px < int(width); px++ {
+			var h float64
+			if width == field.Width() && height == field.Height() {
+				h = float64(field.At(px, int(height)-1-py))
+			} else {
+				h = field.Bicubic(pixelCoordinate(extent.MinX, extent.MaxX, width, px), pixelCoordinate(extent.MaxY, extent.MinY, height, py))
+			}
+			img.SetGray16(px, py, color.Gray16{Y: grayLevel(h, low, high)})
+		}
+	}
+	return png.Encode(w, img)
+}
+
+/*
+ * Spreads count pixels evenly from one world coordinate to another, placing the first pixel on from and the last on to
+ * @param from The world coordinate of the first pixel
+ * @param to The world coordinate of the last pixel
+ * @param count The number of pixels
+ * @param i The index of the pixel
+ */
+func pixelCoordinate(from, to float64, count uint32, i int) float64 {
+	if count < 2 {
+		return from
+	}
+	return from + float64(i)*(to-from)/float64(count-1)
+}
+
+/*
+ * Maps a height onto a 16-bit gray level, clamping heights outside of the range
+ * @param h The height to map
+ * @param low The height that is black
+ * @param high The height that is white
+ */
+func grayLevel(h, low, high float64) uint16 {
+	if high <= low {
+		return 0
+	}
+	t := (h - low) / (high - low)
+	if t < 0 {
+		t = 0
+	} else if t > 1 {
+		t = 1
+	}
+	return uint16(math.Round(t * math.MaxUint16))
+}
@@ -0,0 +1,50 @@
+package export
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math"
+	"testing"
+)
+
+// Heightmaps are 16-bit grayscale pngs whose top row is the highest row of the field, spanning every gray level
+func TestWritePNG16Bit(t *testing.T) {
+	field := testField(33, 17)
+	var buf bytes.Buffer
+	if err := WritePNG(&buf, field, PNGOptions{Normalization: NormalizeMinMax}); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, ok := decoded.(*image.Gray16)
+	if !ok {
+		t.Fatalf("the png decodes to a %T, not a 16-bit grayscale image", decoded)
+	}
+	if img.Bounds() != image.Rect(0, 0, 33, 17) {
+		t.Fatalf("the png is %v, expected 33x17", img.Bounds())
+	}
+
+	low, high := field.Range()
+	lowest, highest := uint16(math.MaxUint16), uint16(0)
+	for py := 0; py < 17; py++ {
+		for px := 0; px < 33; px++ {
+			gray := img.Gray16At(px, py).Y
+			expected := grayLevel(float64(field.At(px, 16-py)), float64(low), float64(high))
+			if gray != expected {
+				t.Fatalf("pixel (%d, %d) is %d, expected %d from row %d of the field", px, py, gray, expected, 16-py)
+			}
+			if gray < lowest {
+				lowest = gray
+			}
+			if gray > highest {
+				highest = gray
+			}
+		}
+	}
+	if lowest != 0 || highest != math.MaxUint16 {
+		t.Errorf("minmax normalization spans gray levels %d to %d, expected 0 to %d", lowest, highest, math.MaxUint16)
+	}
+}
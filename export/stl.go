@@ -0,0 +1,135 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"terrain-generation/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//============================================STL=============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The options of a solid stl model of a terrain region
+type STLOptions struct {
+	// The multiplier of the heights of the surface, above 0
+	Exaggeration float64
+	// The distance from the lowest point of the surface down to the bottom of the base plate
+	BaseThickness float64
+	// The first column and row of the region, and its number of columns and rows. A width or height of 0 covers the
+	// rest of the field from the first column or row.
+	Col, Row      int
+	Width, Height uint32
+}
+
+/*
+ * Writes a region of a height field as a watertight binary stl that can be 3D printed. The model is the surface of the
+ * region with walls down from each of its edges to a flat base plate, and every triangle faces out of the solid.
+ * The model keeps the terrain's z up world coordinates.
+ * @param w The writer the stl is written to
+ * @param field The height field to write
+ * @param options The region, exaggeration and base of the model
+ */
+func WriteSTL(w io.Writer, field *terrain.HeightField, options STLOptions) error {
+	if options.Col < 0 || options.Row < 0 || options.Col >= int(field.Width()) || options.Row >= int(field.Height()) {
+		return fmt.Errorf("the region starts at column %d row %d, outside of the %d x %d field", options.Col, options.Row, field.Width(), field.Height())
+	}
+	width, height := options.Width, options.Height
+	if width == 0 {
+		width = field.Width() - uint32(options.Col)
+	}
+	if height == 0 {
+		height = field.Height() - uint32(options.Row)
+	}
+	if width < 2 || height < 2 || options.Col+int(width) > int(field.Width()) || options.Row+int(height) > int(field.Height()) {
+		return fmt.Errorf("the region of %d x %d vertices does not fit the %d x %d field or has no cells", width, height, field.Width(), field.Height())
+	}
+	if options.BaseThickness <= 0 {
+		return fmt.Errorf("the base of a solid model needs a thickness above 0")
+	}
+	// An exaggeration of 0 would flatten the surface onto the base and a negative one would turn the model inside out
+	if options.Exaggeration <= 0 {
+		return fmt.Errorf("the exaggeration of a solid model needs to be above 0, not %g", options.Exaggeration)
+	}
+	exaggeration := options.Exaggeration
+
+	mesh := terrain.NewRegionMesh(field, options.Col, options.Row, width, height)
+	vertex := func(i uint32) [3]float32 {
+		p := mesh.Positions[i*3 : i*3+3]
+		return [3]float32{p[0], p[1], float32(float64(p[2]) * exaggeration)}
+	}
+	base := math.Inf(1)
+	for i := 0; i < mesh.VertexCount(); i++ {
+		base = math.Min(base, float64(vertex(uint32(i))[2]))
+	}
+	base -= options.BaseThickness
+	bottom := func(i uint32) [3]float32 {
+		v := vertex(i)
+		return [3]float32{v[0], v[1], float32(base)}
+	}
+
+	var triangles [][3][3]float32
+	for i := 0; i < mesh.TriangleCount(); i++ {
+		triangles = append(triangles, [3][3]float32{vertex(mesh.Indices[i*3]), vertex(mesh.Indices[i*3+1]), vertex(mesh.Indices[i*3+2])})
+	}
+
+	// The vertices along the edges of the region, counter-clockwise when viewed from +z
+	var edge []uint32
+	for i := uint32(0); i < width-1; i++ {
+		edge = append(edge, i)
+	}
+	for j := uint32(0); j < height-1; j++ {
+		edge = append(edge, j*width+width-1)
+	}
+	for i := width - 1; i > 0; i-- {
+		edge = append(edge, (height-1)*width+i)
+	}
+	for j := height - 1; j > 0; j-- {
+		edge = append(edge, j*width)
+	}
+
+	// Every wall is a pair of triangles between two neighbouring edge vertices and the base below them, and the base
+	// plate is a fan from its center to the bottom of every wall so that each of its edges is shared with a wall
+	first, last := vertex(0), vertex(height*width-1)
+	center := [3]float32{(first[0] + last[0]) / 2, (first[1] + last[1]) / 2, float32(base)}
+	for k := range edge {
+		a, b := edge[k], edge[(k+1)%len(edge)]
+		triangles = append(triangles,
+			[3][3]float32{bottom(a), bottom(b), vertex(b)},
+			[3][3]float32{bottom(a), vertex(b), vertex(a)},
+			[3][3]float32{center, bottom(b), bottom(a)},
+		)
+	}
+
+	// The model is written to a buffer, which can not fail, so the only error is the one of writing the buffer to w
+	var out bytes.Buffer
+	header := make([]byte, 80)
+	copy(header, fmt.Sprintf("terrain-generation solid of %d x %d vertices", width, height))
+	out.Write(header)
+	binary.Write(&out, binary.LittleEndian, uint32(len(triangles)))
+	for _, triangle := range triangles {
+		binary.Write(&out, binary.LittleEndian, facetNormal(triangle))
+		binary.Write(&out, binary.LittleEndian, triangle)
+		binary.Write(&out, binary.LittleEndian, uint16(0))
+	}
+	_, err := out.WriteTo(w)
+	return err
+}
+
+/*
+ * The unit normal of a counter-clockwise wound triangle
+ * @param triangle The three vertices of the triangle
+ */
+func facetNormal(triangle [3][3]float32) [3]float32 {
+	ux, uy, uz := triangle[1][0]-triangle[0][0], triangle[1][1]-triangle[0][1], triangle[1][2]-triangle[0][2]
+	vx, vy, vz := triangle[2][0]-triangle[0][0], triangle[2][1]-triangle[0][1], triangle[2][2]-triangle[0][2]
+	nx, ny, nz := uy*vz-uz*vy, uz*vx-ux*vz, ux*vy-uy*vx
+	length := float32(math.Sqrt(float64(nx*nx + ny*ny + nz*nz)))
+	if length == 0 {
+		return [3]float32{}
+	}
+	return [3]float32{nx / length, ny / length, nz / length}
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// Solid models are watertight, every edge is shared by exactly two triangles which run along it in opposite directions
+func TestWriteSTLWatertight(t *testing.T) {
+	field := testField(9, 7)
+	regions := []STLOptions{
+		{BaseThickness: 1, Exaggeration: 1},
+		{BaseThickness: 0.5, Exaggeration: 3, Col: 2, Row: 1, Width: 4, Height: 5},
+		{BaseThickness: 2, Exaggeration: 1, Col: 7, Row: 5, Width: 2, Height: 2},
+	}
+	for _, options := range regions {
+		var buf bytes.Buffer
+		if err := WriteSTL(&buf, field, options); err != nil {
+			t.Fatal(err)
+		}
+		data := buf.Bytes()
+		count := binary.LittleEndian.Uint32(data[80:])
+		if len(data) != 84+int(count)*50 {
+			t.Fatalf("%+v: %d bytes for %d triangles", options, len(data), count)
+		}
+		width, height := int(options.Width), int(options.Height)
+		if width == 0 {
+			width, height = int(field.Width()), int(field.Height())
+		}
+		// The surface, a wall pair and a base triangle for every edge cell
+		expected := 2*(width-1)*(height-1) + 3*2*(width-1+height-1)
+		if int(count) != expected {
+			t.Errorf("%+v: %d triangles, expected %d", options, count, expected)
+		}
+
+		type vertex [3]float32
+		edges := map[[2]vertex]int{}
+		for i := 0; i < int(count); i++ {
+			var triangle [3]vertex
+			binary.Read(bytes.NewReader(data[84+i*50+12:84+i*50+48]), binary.LittleEndian, &triangle)
+			for k := 0; k < 3; k++ {
+				edges[[2]vertex{triangle[k], triangle[(k+1)%3]}]++
+			}
+		}
+		for edge, n := range edges {
+			if n != 1 || edges[[2]vertex{edge[1], edge[0]}] != 1 {
+				t.Fatalf("%+v: the edge %v is used %d times and reversed %d times", options, edge, n, edges[[2]vertex{edge[1], edge[0]}])
+			}
+		}
+	}
+}
+
+// The exaggeration multiplies the heights of the surface
+func TestWriteSTLExaggeration(t *testing.T) {
+	field := testField(9, 7)
+	_, high := field.Range()
+	for _, exaggeration := range []float64{1, 3} {
+		var buf bytes.Buffer
+		if err := WriteSTL(&buf, field, STLOptions{BaseThickness: 1, Exaggeration: exaggeration}); err != nil {
+			t.Fatal(err)
+		}
+		data := buf.Bytes()
+		highest := float32(math.Inf(-1))
+		for i := 0; i < int(binary.LittleEndian.Uint32(data[80:])); i++ {
+			var triangle [3][3]float32
+			binary.Read(bytes.NewReader(data[84+i*50+12:84+i*50+48]), binary.LittleEndian, &triangle)
+			for _, v := range triangle {
+				if v[2] > highest {
+					highest = v[2]
+				}
+			}
+		}
+		if want := float32(float64(high) * exaggeration); highest != want {
+			t.Errorf("the highest point of the model with an exaggeration of %v is %v, expected %v", exaggeration, highest, want)
+		}
+	}
+}
+
+// An exaggeration of 0 would flatten the model onto its base and a negative one would turn it inside out, so both are
+// rejected rather than replaced
+func TestWriteSTLBadExaggeration(t *testing.T) {
+	for _, exaggeration := range []float64{0, -1} {
+		var buf bytes.Buffer
+		if err := WriteSTL(&buf, testField(9, 7), STLOptions{BaseThickness: 1, Exaggeration: exaggeration}); err == nil {
+			t.Errorf("a model with an exaggeration of %v was written", exaggeration)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%d bytes were written for a rejected model", buf.Len())
+		}
+	}
+}
+
+// A writer that fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+// A model that can not be written returns the error of its writer
+func TestWriteSTLWriteError(t *testing.T) {
+	if err := WriteSTL(failingWriter{}, testField(9, 7), STLOptions{BaseThickness: 1, Exaggeration: 1}); err == nil {
+		t.Error("writing a model to a failing writer returned no error")
+	}
+}
@@ -0,0 +1,93 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"math"
+	"testing"
+
+	"terrain-generation/terrain"
+)
+
+// The heights of periodic terrains of the shape of maps/tile_test.json, as they are exported
+func periodicFields() map[string]*terrain.HeightField {
+	simple := terrain.NewGradientBoard(9, 9, 43)
+	simple.Tile()
+
+	fractal := terrain.NewFractalBoard(9, 9, 97, 5, 2, 0.5)
+	fractal.Tile()
+
+	warp := terrain.NewDomainWarp(11, 0.6, 1, 2)
+	xBounds, yBounds := fractal.Bounds()
+	warp.UsePeriod(uint32(xBounds.Size()), uint32(yBounds.Size()))
+
+	return map[string]*terrain.HeightField{
+		"simple":  terrain.NewSimpleTerrain(simple, terrain.DomainWarp{}, 65, 49, 1).HeightField(),
+		"fractal": terrain.NewFractalTerrain(fractal, terrain.DomainWarp{}, 65, 49, 1).HeightField(),
+		"warped":  terrain.NewFractalTerrain(fractal, warp, 65, 49, 1).HeightField(),
+	}
+}
+
+/*
+ * Checks that the first and last rows and columns of an exported heightmap match
+ * @param t The test
+ * @param name The name of the terrain the heightmap was exported from
+ * @param w The number of columns of the heightmap
+ * @param h The number of rows of the heightmap
+ * @param at The height of the heightmap at a column and row
+ * @param tolerance The largest difference between opposite edges
+ */
+func checkTiles(t *testing.T, name string, w, h int, at func(col, row int) float64, tolerance float64) {
+	for r := 0; r < h; r++ {
+		if d := math.Abs(at(0, r) - at(w-1, r)); d > tolerance {
+			t.Errorf("%s: row %d differs by %v between the first and last columns", name, r, d)
+		}
+	}
+	for c := 0; c < w; c++ {
+		if d := math.Abs(at(c, 0) - at(c, h-1)); d > tolerance {
+			t.Errorf("%s: column %d differs by %v between the first and last rows", name, c, d)
+		}
+	}
+}
+
+// The exported heightmaps of periodic terrains tile seamlessly, their opposite edges have the same heights
+func TestExportedPeriodicEdges(t *testing.T) {
+	// The warped positions on opposite edges only differ by float32 rounding, see DomainWarp.UsePeriod
+	const tolerance = 1e-4
+	for name, field := range periodicFields() {
+		w, h := int(field.Width()), int(field.Height())
+
+		var r32 bytes.Buffer
+		if err := WriteR32(&r32, field); err != nil {
+			t.Fatal(err)
+		}
+		heights := make([]float32, w*h)
+		if err := binary.Read(&r32, binary.LittleEndian, heights); err != nil {
+			t.Fatalf("%s: the r32 heights can not be read back: %v", name, err)
+		}
+		checkTiles(t, name+" r32", w, h, func(col, row int) float64 {
+			return float64(heights[row*w+col])
+		}, tolerance)
+
+		var buf bytes.Buffer
+		if err := WritePNG(&buf, field, PNGOptions{Normalization: NormalizeMinMax}); err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, ok := decoded.(*image.Gray16)
+		if !ok {
+			t.Fatalf("%s: the png decodes to a %T, not a 16-bit grayscale image", name, decoded)
+		}
+		// The tolerance in gray levels, one more for heights that round to neighbouring levels
+		low, high := field.Range()
+		levels := math.Ceil(tolerance/float64(high-low)*math.MaxUint16) + 1
+		checkTiles(t, name+" png", img.Bounds().Dx(), img.Bounds().Dy(), func(col, row int) float64 {
+			return float64(img.Gray16At(col, row).Y)
+		}, levels)
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=========================================mapFormat==========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The file format of a map
+type mapFormat uint8
+
+const (
+	// Json, the format of the original maps
+	mapFormatJSON mapFormat = iota
+	// Yaml, which allows # comments
+	mapFormatYAML
+	// Toml, which allows # comments and writes every layer as a [[layers]] table
+	mapFormatTOML
+)
+
+// The file formats of maps by the extension of their files, in the order the search path tries them
+var mapFormatExtensions = []struct {
+	ext    string
+	format mapFormat
+}{
+	{".json", mapFormatJSON},
+	{".yaml", mapFormatYAML},
+	{".yml", mapFormatYAML},
+	{".toml", mapFormatTOML},
+}
+
+// The names of the map formats
+var mapFormatNames = map[mapFormat]string{
+	mapFormatJSON: "json",
+	mapFormatYAML: "yaml",
+	mapFormatTOML: "toml",
+}
+
+func (format mapFormat) String() string {
+	return mapFormatNames[format]
+}
+
+/*
+ * Parses the name of a map format, which is also the extension of its files
+ * @param name The name of the format, json, yaml, yml or toml
+ */
+func parseMapFormat(name string) (mapFormat, error) {
+	if format, ok := mapFormatOf("." + strings.TrimPrefix(name, ".")); ok {
+		return format, nil
+	}
+	return 0, fmt.Errorf("unknown map format %q, expected one of %s", name, mapExtensions())
+}
+
+// The extensions of map files, in the order the search path tries them
+func mapExtensions() string {
+	exts := make([]string, len(mapFormatExtensions))
+	for i, f := range mapFormatExtensions {
+		exts[i] = f.ext
+	}
+	return strings.Join(exts, ", ")
+}
+
+/*
+ * Finds the file format of a map by the extension of its path
+ * @param path The path of the map file
+ */
+func mapFormatOf(path string) (mapFormat, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, f := range mapFormatExtensions {
+		if f.ext == ext {
+			return f.format, true
+		}
+	}
+	return 0, false
+}
+
+/*
+ * Parses a map file into the fields of the map. The values of yaml and toml maps are converted into the values
+ * encoding/json decodes, numbers into float64 and objects into map[string]interface{}, so every format is decoded the same way.
+ * @param data The contents of the map file
+ */
+func (format mapFormat) unmarshal(data []byte) (interface{}, error) {
+	var v interface{}
+	var err error
+	switch format {
+	case mapFormatJSON:
+		err = json.Unmarshal(data, &v)
+	case mapFormatYAML:
+		err = yaml.Unmarshal(data, &v)
+	case mapFormatTOML:
+		var table map[string]interface{}
+		_, err = toml.Decode(string(data), &table)
+		v = table
+	}
+	if err != nil {
+		return nil, err
+	}
+	return normalizeMapValue(v), nil
+}
+
+/*
+ * Converts a yaml or toml value into the value encoding/json would decode
+ * @param v The value
+ */
+func normalizeMapValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			m[fmt.Sprint(k)] = normalizeMapValue(value)
+		}
+		return m
+	case map[string]interface{}:
+		for k, value := range v {
+			v[k] = normalizeMapValue(value)
+		}
+		return v
+	case []interface{}:
+		for i, value := range v {
+			v[i] = normalizeMapValue(value)
+		}
+		return v
+	case []map[string]interface{}:
+		values := make([]interface{}, len(v))
+		for i, value := range v {
+			values[i] = normalizeMapValue(value)
+		}
+		return values
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return v
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//==========================================Writing===========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The fields of a map in the order they are written, the map it extends and then the order of the fields of TerrainMap
+var terrainMapKeys = []string{"extends", "typ", "gradient_width_b1", "gradient_height_b1", "gradient_width_b2", "gradient_height_b2",
+	"seed1", "seed2", "noise_b1", "noise_b2", "distance_b1", "distance_b2", "metric_b1", "metric_b2", "m", "prop", "octaves",
+	"lacunarity", "persistence", "fractal", "ridge_offset", "gain", "sharpness", "warp_strength", "warp_seed", "warp_frequency", "warp_iterations", "warp_noise", "periodic", "layers"}
+
+// The fields of a layer in the order they are written, the order of the fields of TerrainMapLayer
+var terrainMapLayerKeys = []string{"gradient_width", "gradient_height", "seed", "noise", "distance", "metric", "weight",
+	"offset", "blend", "mask", "fractal", "octaves", "lacunarity", "persistence", "ridge_offset", "gain", "sharpness",
+	"heightmap", "heightmap_width", "heightmap_height"}
+
+// A field of a map or layer, in the order it is written
+type mapField struct {
+	key   string
+	value interface{}
+}
+
+/*
+ * Orders the fields of a map or layer, any fields that are not in the order come last in alphabetical order. Fields
+ * without a value (a json null or an empty yaml field) are left out, toml has no way to write them.
+ * @param m The fields
+ * @param order The keys of the fields in the order they are written
+ */
+func orderMapFields(m map[string]interface{}, order []string) []mapField {
+	fields := make([]mapField, 0, len(m))
+	known := make(map[string]bool, len(order))
+	for _, k := range order {
+		known[k] = true
+		if v, ok := m[k]; ok && v != nil {
+			fields = append(fields, mapField{k, v})
+		}
+	}
+	var rest []string
+	for k, v := range m {
+		if !known[k] && v != nil {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	for _, k := range rest {
+		fields = append(fields, mapField{k, m[k]})
+	}
+	return fields
+}
+
+/*
+ * Writes the fields of a map in a file format. Every layer is written on its own, the way the maps in /maps are written.
+ * @param m The fields of the map, as unmarshal returns them
+ */
+func (format mapFormat) marshal(m map[string]interface{}) ([]byte, error) {
+	fields := orderMapFields(m, terrainMapKeys)
+	// Layers that are not objects have no fields to order, so they are reported rather than written as empty layers
+	var layers [][]mapField
+	if v, ok := m["layers"]; ok && v != nil {
+		values, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("layers: %s, expected an array of layers", describeValue(v))
+		}
+		for i, v := range values {
+			layer, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("layers[%d]: %s, a layer is an object", i, describeValue(v))
+			}
+			layers = append(layers, orderMapFields(layer, terrainMapLayerKeys))
+		}
+	}
+
+	var out bytes.Buffer
+	switch format {
+	case mapFormatJSON:
+		out.WriteString("{\n")
+		for i, f := range fields {
+			if i > 0 {
+				out.WriteString(",\n")
+			}
+			fmt.Fprintf(&out, "    %s: ", jsonKey(f.key))
+			if f.key == "layers" && len(layers) == 0 {
+				out.WriteString("[]")
+				continue
+			}
+			if f.key == "layers" {
+				out.WriteString("[\n")
+				for j, layer := range layers {
+					if j > 0 {
+						out.WriteString(",\n")
+					}
+					out.WriteString("        {")
+					for k, lf := range layer {
+						if k > 0 {
+							out.WriteString(", ")
+						}
+						value, err := json.Marshal(lf.value)
+						if err != nil {
+							return nil, err
+						}
+						fmt.Fprintf(&out, "%s: %s", jsonKey(lf.key), value)
+					}
+					out.WriteString("}")
+				}
+				out.WriteString("\n    ]")
+				continue
+			}
+			value, err := json.Marshal(f.value)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(value)
+		}
+		out.WriteString("\n}\n")
+	case mapFormatYAML:
+		doc := yaml.MapSlice{}
+		for _, f := range fields {
+			if f.key == "layers" {
+				slices := make([]yaml.MapSlice, len(layers))
+				for j, layer := range layers {
+					for _, lf := range layer {
+						slices[j] = append(slices[j], yaml.MapItem{Key: lf.key, Value: lf.value})
+					}
+				}
+				doc = append(doc, yaml.MapItem{Key: f.key, Value: slices})
+				continue
+			}
+			doc = append(doc, yaml.MapItem{Key: f.key, Value: f.value})
+		}
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(data)
+	case mapFormatTOML:
+		// The layers are tables, which have to come after every other field. An empty array of layers has no tables, so
+		// it is written as a field to keep it in the map.
+		for _, f := range fields {
+			if f.key != "layers" {
+				value, err := tomlValue(f.value)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", f.key, err)
+				}
+				fmt.Fprintf(&out, "%s = %s\n", tomlKey(f.key), value)
+			} else if len(layers) == 0 {
+				out.WriteString("layers = []\n")
+			}
+		}
+		for i, layer := range layers {
+			out.WriteString("\n[[layers]]\n")
+			for _, lf := range layer {
+				value, err := tomlValue(lf.value)
+				if err != nil {
+					return nil, fmt.Errorf("layers[%d].%s: %v", i, lf.key, err)
+				}
+				fmt.Fprintf(&out, "%s = %s\n", tomlKey(lf.key), value)
+			}
+		}
+	}
+	return out.Bytes(), nil
+}
+
+/*
+ * Formats the key of a json field as a json string, which escapes characters differently from the quoting of go strings
+ * @param key The key of the field
+ */
+func jsonKey(key string) string {
+	data, _ := json.Marshal(key)
+	return string(data)
+}
+
+/*
+ * Formats a field as a toml value. Whole numbers that fit an int64 are written as integers, arrays as toml arrays and
+ * tables as inline tables. It returns an error for values toml can not hold, like null.
+ * @param v The value of the field
+ */
+func tomlValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "nan", nil
+		case math.IsInf(v, 1):
+			return "inf", nil
+		case math.IsInf(v, -1):
+			return "-inf", nil
+		case v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64:
+			return strconv.FormatInt(int64(v), 10), nil
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		// A json string is a toml basic string for the characters maps hold
+		data, err := json.Marshal(v)
+		return string(data), err
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, value := range v {
+			s, err := tomlValue(value)
+			if err != nil {
+				return "", err
+			}
+			values[i] = s
+		}
+		return "[" + strings.Join(values, ", ") + "]", nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]string, len(keys))
+		for i, k := range keys {
+			s, err := tomlValue(v[k])
+			if err != nil {
+				return "", err
+			}
+			values[i] = tomlKey(k) + " = " + s
+		}
+		return "{" + strings.Join(values, ", ") + "}", nil
+	}
+	return "", fmt.Errorf("a %T can not be written to toml", v)
+}
+
+/*
+ * Formats the key of a toml field, quoting it unless it is a bare key of letters, digits, underscores and dashes
+ * @param key The key of the field
+ */
+func tomlKey(key string) string {
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			data, _ := json.Marshal(key)
+			return string(data)
+		}
+	}
+	if key == "" {
+		return `""`
+	}
+	return key
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// A map converted from json to toml to yaml and back to json decodes to the same terrain map in every format
+func TestConvertRoundTrip(t *testing.T) {
+	source, m, err := readTerrainMapFields("layered_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := decodeTerrainMap(resolvedMap{fields: m})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ext := range []string{".toml", ".yaml", ".json"} {
+		format, _ := mapFormatOf(ext)
+		data, err := format.marshal(m)
+		if err != nil {
+			t.Fatalf("%s: %v", ext, err)
+		}
+		if m, err = parseTerrainMap(source.path+ext, data); err != nil {
+			t.Fatalf("%s: %v\n%s", ext, err, data)
+		}
+		got, err := decodeTerrainMap(resolvedMap{fields: m})
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", ext, err, data)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: decoded to\n%+v\nexpected\n%+v", ext, got, want)
+		}
+	}
+}
+
+// Yaml and toml numbers and tables decode to the values encoding/json decodes, comments included
+func TestNormalizeMapValue(t *testing.T) {
+	maps := map[string]string{
+		"map.yaml": "# a comment\ntyp: 4 # whole numbers are ints in yaml\nm: 1.5\nlayers:\n  - gradient_width: 5\n    blend: add\n",
+		"map.toml": "# a comment\ntyp = 4\nm = 1.5\n\n[[layers]]\ngradient_width = 5\nblend = \"add\"\n",
+	}
+	want := map[string]interface{}{
+		"typ":    float64(4),
+		"m":      1.5,
+		"layers": []interface{}{map[string]interface{}{"gradient_width": float64(5), "blend": "add"}},
+	}
+	for file, data := range maps {
+		got, err := parseTerrainMap(file, []byte(data))
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: parsed to %#v, expected %#v", file, got, want)
+		}
+	}
+}
+
+// The convert command writes a map in the format of the extension of its output file, or the format of -format
+func TestWriteMapFields(t *testing.T) {
+	_, m, err := readTerrainMapFields("warped_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := readTerrainMap("warped_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	outputs := []struct{ file, format string }{{"warped.toml", ""}, {"warped.yml", ""}, {"warped.json", "json"}}
+	for _, output := range outputs {
+		path := filepath.Join(dir, output.file)
+		if err := writeMapFields(m, output.format, []string{path}); err != nil {
+			t.Fatalf("%s: %v", output.file, err)
+		}
+		got, err := readTerrainMap(path)
+		if err != nil {
+			t.Fatalf("%s: %v", output.file, err)
+		}
+		got.source, got.layerSource = want.source, want.layerSource
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: read back as\n%+v\nexpected\n%+v", output.file, got, want)
+		}
+	}
+	if err := writeMapFields(m, "", []string{filepath.Join(dir, "warped.txt")}); err == nil {
+		t.Error("a map was written to a file without the extension of a map format")
+	}
+}
+
+// Fields without a value are left out and an empty array of layers is kept, in every format
+func TestMarshalNullsAndEmptyLayers(t *testing.T) {
+	m := map[string]interface{}{"typ": float64(4), "m": 1.5, "seed1": nil, "unknown": nil, "layers": []interface{}{}}
+	want := map[string]interface{}{"typ": float64(4), "m": 1.5, "layers": []interface{}{}}
+	for _, ext := range []string{".json", ".yaml", ".toml"} {
+		format, _ := mapFormatOf(ext)
+		data, err := format.marshal(m)
+		if err != nil {
+			t.Fatalf("%s: %v", ext, err)
+		}
+		got, err := parseTerrainMap("map"+ext, data)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", ext, err, data)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: parsed back to %#v, expected %#v\n%s", ext, got, want, data)
+		}
+	}
+}
+
+// Layers that are not an array, or entries of the layers that are not objects, are reported in every format instead of
+// being written as empty layers
+func TestMarshalBadLayers(t *testing.T) {
+	cases := []struct {
+		layers interface{}
+		field  string
+	}{
+		{"mountains", "layers:"},
+		{map[string]interface{}{"seed": float64(43)}, "layers:"},
+		{[]interface{}{map[string]interface{}{"seed": float64(43)}, float64(7)}, "layers[1]:"},
+		{[]interface{}{[]interface{}{}}, "layers[0]:"},
+	}
+	for _, c := range cases {
+		for _, ext := range []string{".json", ".yaml", ".toml"} {
+			format, _ := mapFormatOf(ext)
+			m := map[string]interface{}{"typ": float64(4), "layers": c.layers}
+			if data, err := format.marshal(m); err == nil || !strings.HasPrefix(err.Error(), c.field) {
+				t.Errorf("%s: layers %#v are reported as %v, expected a problem with %s\n%s", ext, c.layers, err, c.field, data)
+			}
+		}
+	}
+}
+
+// The keys of a json map are written as json strings, which escape characters the quoting of go strings does not
+func TestMarshalJSONKeys(t *testing.T) {
+	m := map[string]interface{}{"typ": float64(1), "caf\u00e9 \x7f<tag>": float64(2),
+		"layers": []interface{}{map[string]interface{}{"\u2028": true}}}
+	data, err := mapFormatJSON.marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseTerrainMap("map.json", data)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("parsed back to %#v, expected %#v\n%s", got, m, data)
+	}
+}
+
+// Converting a map with layers to toml and back keeps every layer, and the arrays and tables of fields the program does not read
+func TestConvertTOMLLayers(t *testing.T) {
+	m := map[string]interface{}{
+		"typ": float64(4),
+		"m":   1.5,
+		"layers": []interface{}{
+			map[string]interface{}{"gradient_width": float64(5), "gradient_height": float64(5), "seed": float64(43), "weight": 0.8, "blend": "add"},
+			map[string]interface{}{"gradient_width": float64(27), "gradient_height": float64(27), "seed": float64(97), "weight": 0.2, "blend": "lerp", "mask": float64(0)},
+		},
+		"tags":          []interface{}{"alpine", float64(2), true},
+		"notes":         map[string]interface{}{"author": "designer", "sizes": []interface{}{float64(65), 0.5}},
+		"big":           1e20,
+		"quoted key":    "value",
+		"large integer": float64(1 << 62),
+	}
+	data, err := mapFormatTOML.marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseTerrainMap("map.toml", data)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("parsed back to %#v, expected %#v\n%s", got, m, data)
+	}
+
+	if _, err := mapFormatTOML.marshal(map[string]interface{}{"tags": []interface{}{nil}}); err == nil {
+		t.Error("an array holding null was written to toml")
+	}
+}
+
+// Whole numbers are integers only when they fit an int64, larger ones stay floats
+func TestTOMLValue(t *testing.T) {
+	values := map[float64]string{3: "3", -2: "-2", 0.5: "0.5", 1 << 62: "4611686018427387904", -(1 << 63): "-9223372036854775808", 1 << 63: "9.223372036854776e+18", 1e300: "1e+300"}
+	for v, want := range values {
+		if got, err := tomlValue(v); err != nil || got != want {
+			t.Errorf("%v is written as %q (%v), expected %q", v, got, err, want)
+		}
+	}
+}
@@ -3,13 +3,13 @@ module terrain-generation
 go 1.18
 
 require (
-	github.com/g3n/demos/hellog3n v0.0.0-20211022214722-2e116c959722 // indirect
-	github.com/g3n/engine v0.2.0 // indirect
-	github.com/go-gl/gl v0.0.0-20211210172815-726fda9656d6 // indirect
-	github.com/go-gl/glfw v0.0.0-20220516021902-eb3e265c7661 // indirect
+	github.com/BurntSushi/toml v1.2.1
+	github.com/g3n/engine v0.2.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20220516021902-eb3e265c7661 // indirect
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	golang.org/x/image v0.0.0-20220601225756-64ec528b34cd // indirect
-	gonum.org/v1/gonum v0.11.0 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
@@ -1,30 +1,15 @@
 package main
 
 import (
-	"embed"
-	"encoding/json"
 	"fmt"
-	"os"
-	"strconv"
-	"time"
+	"io/fs"
+	"math"
+	"path/filepath"
+	"strings"
 
-	"github.com/g3n/engine/app"
-	"github.com/g3n/engine/camera"
-	"github.com/g3n/engine/core"
-	"github.com/g3n/engine/gls"
-	"github.com/g3n/engine/graphic"
-	"github.com/g3n/engine/gui"
-	"github.com/g3n/engine/light"
-	"github.com/g3n/engine/material"
-	"github.com/g3n/engine/math32"
-	"github.com/g3n/engine/renderer"
-	"github.com/g3n/engine/util/helper"
-	"github.com/g3n/engine/window"
+	"terrain-generation/terrain"
 )
 
-//go:embed maps/*
-var file embed.FS
-
 type TerrainMap struct {
 	typ uint8
 	// Gradient widths need to be odd numbers
@@ -36,254 +21,470 @@ type TerrainMap struct {
 	seed1 int32
 	// Seed for the micro gradient board
 	seed2 int32
+	// Noise backends of the macro and micro gradient boards: perlin (default), simplex or worley
+	noise_b1 string
+	noise_b2 string
+	// Feature point distances of worley macro and micro gradient boards: f1 (default), f2 or f2-f1
+	distance_b1 string
+	distance_b2 string
+	// Distance metrics of worley macro and micro gradient boards: euclidean (default), manhattan or chebyshev
+	metric_b1 string
+	metric_b2 string
 	// Magnitude / Amplitude of the terrain
 	m float32
 	// The significiance of macro and micro componenets of the bipartite terrain
 	prop float32
+	// The number of octaves summed by the fractal terrain
+	octaves uint32
+	// The frequency multiplier between consecutive octaves of the fractal terrain
+	lacunarity float32
+	// The amplitude multiplier between consecutive octaves of the fractal terrain
+	persistence float32
+	// The style of the fractal terrain: fbm (default), ridged or billow
+	fractal string
+	// The height of the ridges of a ridged fractal terrain before they are sharpened
+	ridge_offset float32
+	// How strongly each octave of a ridged fractal terrain weights the detail of the next octave
+	gain float32
+	// The exponent that narrows the ridges of a ridged fractal terrain
+	sharpness float32
+	// The layers of the layered terrain
+	layers []TerrainMapLayer
+	// The distance that the domain warp moves sampled positions by, 0 (default) turns the warp off
+	warp_strength float32
+	// Seed for the warp gradient boards
+	warp_seed int32
+	// The frequency of the warp gradient boards relative to the terrain's boards
+	warp_frequency float32
+	// The number of times the warp is applied to each position
+	warp_iterations uint32
+	// Noise backend of the warp gradient boards: perlin (default), simplex or worley
+	warp_noise string
+	// Whether the gradients of every board repeat across the board's bounds, so the terrain tiles seamlessly
+	periodic bool
+	// Where the map was read from, it is not a field of map files
+	source mapSource
+	// Where the layers were read from, which is a map it extends when it does not list its own layers. The heightmaps
+	// of the layers are relative to it.
+	layerSource mapSource
 }
 
-func prepareScene(cam_multipliler uint32) (*app.Application, *core.Node, *camera.Camera) {
-	// Create application and scene
-	a := app.App()
-	scene := core.NewNode()
-
-	// Set the scene to be managed by the gui manager
-	gui.Manager().Set(scene)
-
-	// Create perspective camera
-	cam := camera.New(1)
-	camPosition := float32(cam_multipliler)
-	cam.SetPosition(-camPosition/2, -camPosition*1.7, camPosition*1.7)
-	cam.LookAt(&math32.Vector3{0, 0, -1.2}, &math32.Vector3{0, 0, 1})
-	scene.Add(cam)
+type TerrainMapLayer struct {
+	// Gradient widths need to be odd numbers
+	gradient_width  uint32
+	gradient_height uint32
+	// Seed for the layer's gradient board
+	seed int32
+	// Noise backend of the layer's gradient board: perlin (default), simplex or worley
+	noise string
+	// Feature point distance of a worley layer: f1 (default), f2 or f2-f1
+	distance string
+	// Distance metric of a worley layer: euclidean (default), manhattan or chebyshev
+	metric string
+	// Amplitude of the layer's noise
+	weight float32
+	// Constant added to the layer's noise after it is weighted
+	offset float32
+	// Name of the operator blending the layer with the layers beneath it: add, multiply, max, min, lerp or none
+	blend string
+	// Index of the layer whose value interpolates a lerp layer
+	mask int
+	// The type of the layer: fbm (default), ridged or billow, with the same octave and ridge fields as a fractal terrain
+	fractal      string
+	octaves      uint32
+	lacunarity   float32
+	persistence  float32
+	ridge_offset float32
+	gain         float32
+	sharpness    float32
+	// Path of a png, r16 or r32 heightmap used as the layer's values in place of noise, stretched over the layer's gradient board.
+	// The path is relative to the directory of the map file.
+	heightmap string
+	// The number of heights in a row and the number of rows of a raw heightmap, a square heightmap when they are 0
+	heightmap_width  uint32
+	heightmap_height uint32
+}
 
-	// Set up orbit control for the camera
-	camera.NewOrbitControl(cam)
+/*
+ * Generates the simple terrain described by a terrain map
+ * @param terrainMap The terrain map of the terrain
+ * @param terrainWidth The number of vertices to be sampled in the x direction of the terrain
+ * @param terrainHeight The number of vertices to be sampled in the y direction of the terrain
+ */
+func buildSimpleTerrain(terrainMap TerrainMap, terrainWidth, terrainHeight uint32) (terrain.Terrain, error) {
+	board, err := mapBoard(terrainMap.gradient_width_b1, terrainMap.gradient_height_b1, terrainMap.seed1, terrainMap.noise_b1, terrainMap.distance_b1, terrainMap.metric_b1, terrainMap.periodic)
+	if err != nil {
+		return nil, err
+	}
 
-	// Set up callback to update viewport and camera aspect ratio when the window is resized
-	onResize := func(evname string, ev interface{}) {
-		// Get framebuffer size and update viewport accordingly
-		width, height := a.GetSize()
-		a.Gls().Viewport(0, 0, int32(width), int32(height))
-		// Update the camera's aspect ratio
-		cam.SetAspect(float32(width) / float32(height))
-	}
-	a.Subscribe(window.OnWindowSize, onResize)
-	onResize("", nil)
+	warp, err := mapWarp(terrainMap)
+	if err != nil {
+		return nil, err
+	}
+	if terrainMap.periodic {
+		xBounds, yBounds := board.Bounds()
+		warp.UsePeriod(uint32(xBounds.Size()), uint32(yBounds.Size()))
+	}
 
-	return a, scene, cam
+	return terrain.NewSimpleTerrain(board, warp, terrainWidth, terrainHeight, terrainMap.m), nil
 }
 
-func completeScene(a *app.Application, scene *core.Node, terrain Terrain, cam *camera.Camera) {
-	// Variables to keep track of the current dispacement from the terrain origin
-	xDisp := 0
-	yDisp := 0
+/*
+ * Generates the fractal terrain described by a terrain map
+ * @param terrainMap The terrain map of the terrain
+ * @param terrainWidth The number of vertices to be sampled in the x direction of the terrain
+ * @param terrainHeight The number of vertices to be sampled in the y direction of the terrain
+ */
+func buildFractalTerrain(terrainMap TerrainMap, terrainWidth, terrainHeight uint32) (terrain.Terrain, error) {
+	fractal := terrain.NewFractalBoard(terrainMap.gradient_width_b1, terrainMap.gradient_height_b1, terrainMap.seed1, terrainMap.octaves, terrainMap.lacunarity, terrainMap.persistence)
+	backend, err := mapBackend(terrainMap.noise_b1, terrainMap.distance_b1, terrainMap.metric_b1, terrainMap.periodic)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPeriodicFractal(terrainMap.periodic, terrainMap.gradient_width_b1, terrainMap.gradient_height_b1, terrainMap.octaves, terrainMap.lacunarity); err != nil {
+		return nil, fmt.Errorf("lacunarity %v", err)
+	}
+	fractal.UseBackend(backend)
+	style, ok := terrain.ParseFractalStyle(terrainMap.fractal)
+	if !ok {
+		return nil, fmt.Errorf("unknown fractal style %q", terrainMap.fractal)
+	}
+	fractal.UseStyle(style, terrainMap.ridge_offset, terrainMap.gain, terrainMap.sharpness)
 
-	// Label for Y slider
-	sliderYTitle := gui.NewLabel("Y")
-	sliderYTitle.SetPosition(8, 3)
-	//sliderYTitle.SetSize(5.0, 5.0)
-	scene.Add(sliderYTitle)
+	warp, err := mapWarp(terrainMap)
+	if err != nil {
+		return nil, err
+	}
+	if terrainMap.periodic {
+		fractal.Tile()
+		xBounds, yBounds := fractal.Bounds()
+		warp.UsePeriod(uint32(xBounds.Size()), uint32(yBounds.Size()))
+	}
 
-	// Label for X slider
-	sliderXTitle := gui.NewLabel("X")
-	sliderXTitle.SetPosition(18, 3)
-	//sliderXTitle.SetSize(5.0, 5.0)
-	scene.Add(sliderXTitle)
+	return terrain.NewFractalTerrain(fractal, warp, terrainWidth, terrainHeight, terrainMap.m), nil
+}
 
-	// Y Slider for changing the rendered terrain in the Y direction
-	ySlider := gui.NewVScrollBar(8, 570)
-	ySlider.SetPosition(8, 20)
-	ySlider.SetScale(0, 570, 0)
-	ySlider.SetValue(0.5)
-	ySlider.Subscribe(gui.OnChange, func(name string, ev interface{}) {
-		if int(ySlider.Value()*570)-285 > yDisp {
-			terrain.MoveDown(yDisp - (int(ySlider.Value()*570) - 285))
-		} else if int(ySlider.Value()*570)-285 < yDisp {
-			terrain.MoveUp(yDisp - (int(ySlider.Value()*570) - 285))
+/*
+ * Generates the layered terrain described by a terrain map
+ * @param terrainMap The terrain map of the terrain
+ * @param terrainWidth The number of vertices to be sampled in the x direction of the terrain
+ * @param terrainHeight The number of vertices to be sampled in the y direction of the terrain
+ */
+func buildLayeredTerrain(terrainMap TerrainMap, terrainWidth, terrainHeight uint32) (terrain.Terrain, error) {
+	if len(terrainMap.layers) == 0 {
+		return nil, fmt.Errorf("a layered terrain needs at least one layer")
+	}
+	layers := make([]terrain.TerrainLayer, len(terrainMap.layers))
+	for i, layerMap := range terrainMap.layers {
+		blend, ok := terrain.ParseBlend(layerMap.blend)
+		if !ok {
+			return nil, fmt.Errorf("layer %d has an unknown blend %q", i, layerMap.blend)
 		}
-		yDisp = int(ySlider.Value()*570) - 285
-	})
-	scene.Add(ySlider)
-
-	// X Slider for changing the rendered terrain in the X direction
-	xSlider := gui.NewVScrollBar(8, 570)
-	xSlider.SetPosition(18, 20)
-	xSlider.SetScale(0, 570, 0)
-	xSlider.SetValue(0.5)
-	xSlider.Subscribe(gui.OnChange, func(name string, ev interface{}) {
-		if int(xSlider.Value()*570)-285 > xDisp {
-			terrain.MoveLeft(xDisp - (int(xSlider.Value()*570) - 285))
-		} else if int(xSlider.Value()*570)-285 < xDisp {
-			terrain.MoveRight(xDisp - (int(xSlider.Value()*570) - 285))
+		if blend == terrain.BlendLerp && (layerMap.mask < 0 || layerMap.mask >= len(terrainMap.layers)) {
+			return nil, fmt.Errorf("layer %d has a mask that is not one of the layers", i)
 		}
-		xDisp = int(xSlider.Value()*570) - 285
-	})
-	scene.Add(xSlider)
-
-	// water plane
-	//waterGeometry := geometry.NewPlane(GRADIENT_WIDTH_B1-1, GRADIENT_HEIGHT_B1-1)
-	//waterColor := material.NewStandard(math32.NewColor("darkblue"))
-	//water := graphic.NewMesh(waterGeometry, waterColor)
-	//waterGeometry.OperateOnVertices(func(vertex *math32.Vector3) bool {
-	//	vertex.Z = -0.2 * M
-	//	return false
-	//})
-	//scene.Add(water)
-
-	// Create and add lights to the scene
-	scene.Add(light.NewAmbient(&math32.Color{1.0, 1.0, 1.0}, 0.5))
-	light := light.NewPoint(&math32.Color{1, 1, 1}, 5)
-	light.SetPositionVec(math32.NewVector3(-8, 10, 8))
-	light.SetLinearDecay(0.2)
-	light.SetQuadraticDecay(0)
-	scene.Add(light)
-
-	// Create and add an axis helper to the scene
-	scene.Add(helper.NewAxes(0.5))
+		if layerMap.heightmap != "" {
+			if terrainMap.periodic {
+				return nil, fmt.Errorf("layer %d: a heightmap can not be periodic", i)
+			}
+			heightmap, err := mapHeightmap(terrainMap.layerSource, layerMap)
+			if err != nil {
+				return nil, fmt.Errorf("layer %d: %v", i, err)
+			}
+			layers[i] = terrain.NewTerrainLayer(heightmap, layerMap.weight, layerMap.offset, blend, layerMap.mask)
+			continue
+		}
+		backend, err := mapBackend(layerMap.noise, layerMap.distance, layerMap.metric, terrainMap.periodic)
+		if err != nil {
+			return nil, fmt.Errorf("layer %d: %v", i, err)
+		}
+		style, ok := terrain.ParseFractalStyle(layerMap.fractal)
+		if !ok {
+			return nil, fmt.Errorf("layer %d has an unknown fractal style %q", i, layerMap.fractal)
+		}
+		if err := checkPeriodicFractal(terrainMap.periodic, layerMap.gradient_width, layerMap.gradient_height, layerMap.octaves, layerMap.lacunarity); err != nil {
+			return nil, fmt.Errorf("layer %d: lacunarity %v", i, err)
+		}
+		fractal := terrain.NewFractalBoard(layerMap.gradient_width, layerMap.gradient_height, layerMap.seed, layerMap.octaves, layerMap.lacunarity, layerMap.persistence)
+		fractal.UseBackend(backend)
+		fractal.UseStyle(style, layerMap.ridge_offset, layerMap.gain, layerMap.sharpness)
+		if terrainMap.periodic {
+			fractal.Tile()
+		}
+		layers[i] = terrain.NewTerrainLayer(fractal, layerMap.weight, layerMap.offset, blend, layerMap.mask)
+	}
 
-	// Set background color to gray
-	a.Gls().ClearColor(0.3, 0.3, 0.3, 1.0)
+	warp, err := mapWarp(terrainMap)
+	if err != nil {
+		return nil, err
+	}
+	if terrainMap.periodic {
+		xBounds, yBounds := layers[0].Source().Bounds()
+		warp.UsePeriod(uint32(xBounds.Size()), uint32(yBounds.Size()))
+	}
 
-	// Run the application
-	a.Run(func(renderer *renderer.Renderer, deltaTime time.Duration) {
-		a.Gls().Clear(gls.DEPTH_BUFFER_BIT | gls.STENCIL_BUFFER_BIT | gls.COLOR_BUFFER_BIT)
-		renderer.Render(scene, cam)
-	})
+	return terrain.NewLayeredTerrain(layers, warp, terrainWidth, terrainHeight, terrainMap.m), nil
 }
 
-func renderSimpleTerrain(terrainMap TerrainMap, terrainWidth, terrainHeight uint32) {
-	var board GradientBoard
-	board.initialize(terrainMap.gradient_width_b1, terrainMap.gradient_height_b1, terrainMap.seed1)
+/*
+ * Generates the bipartite terrain described by a terrain map
+ * @param terrainMap The terrain map of the terrain
+ * @param terrainWidth The number of vertices to be sampled in the x direction of the terrain
+ * @param terrainHeight The number of vertices to be sampled in the y direction of the terrain
+ */
+func buildBipartiteTerrain(terrainMap TerrainMap, terrainWidth, terrainHeight uint32) (terrain.Terrain, error) {
+	macro, err := mapBoard(terrainMap.gradient_width_b1, terrainMap.gradient_height_b1, terrainMap.seed1, terrainMap.noise_b1, terrainMap.distance_b1, terrainMap.metric_b1, terrainMap.periodic)
+	if err != nil {
+		return nil, err
+	}
+	micro, err := mapBoard(terrainMap.gradient_width_b2, terrainMap.gradient_height_b2, terrainMap.seed2, terrainMap.noise_b2, terrainMap.distance_b2, terrainMap.metric_b2, terrainMap.periodic)
+	if err != nil {
+		return nil, err
+	}
 
-	a, scene, cam := prepareScene(terrainMap.gradient_height_b1 / 2)
+	warp, err := mapWarp(terrainMap)
+	if err != nil {
+		return nil, err
+	}
+	if terrainMap.periodic {
+		xBounds, yBounds := macro.Bounds()
+		warp.UsePeriod(uint32(xBounds.Size()), uint32(yBounds.Size()))
+	}
 
-	terrain := new(SimpleTerrain)
-	terrain.initialize(board, terrainWidth, terrainHeight, terrainMap.m)
-	mat := material.NewStandard(math32.NewColor("darkgrey"))
-	mesh := graphic.NewMesh(terrain.geom, mat)
-	scene.Add(mesh)
+	return terrain.NewBipartiteTerrain(macro, micro, warp, terrainWidth, terrainHeight, terrainMap.m, terrainMap.prop), nil
+}
 
-	completeScene(a, scene, terrain, cam)
+/*
+ * Creates a gradient board from the fields of a map file
+ * @param gradientWidth The number of gradients in the X-direction of the board
+ * @param gradientHeight The number of gradients in the Y-direction of the board
+ * @param seed The seed of the board
+ * @param noise The name of the noise backend evaluated by the board
+ * @param distance The name of the feature point distance of a worley board
+ * @param metric The name of the distance metric of a worley board
+ * @param periodic Whether the gradients of the board repeat across its bounds
+ */
+func mapBoard(gradientWidth, gradientHeight uint32, seed int32, noise, distance, metric string, periodic bool) (terrain.GradientBoard, error) {
+	board := terrain.NewGradientBoard(gradientWidth, gradientHeight, seed)
+	backend, err := mapBackend(noise, distance, metric, periodic)
+	if err != nil {
+		return board, err
+	}
+	board.UseBackend(backend)
+	if periodic {
+		board.Tile()
+	}
+	return board, nil
 }
 
-func renderBipartiteTerrain(terrainMap TerrainMap, terrainWidth, terrainHeight uint32) {
-	var macro GradientBoard
-	var micro GradientBoard
-	macro.initialize(terrainMap.gradient_width_b1, terrainMap.gradient_height_b1, terrainMap.seed1)
-	micro.initialize(terrainMap.gradient_width_b2, terrainMap.gradient_height_b2, terrainMap.seed2)
+/*
+ * Creates the noise backend of a board from the fields of a map file
+ * @param noise The name of the noise backend
+ * @param distance The name of the feature point distance of a worley backend
+ * @param metric The name of the distance metric of a worley backend
+ * @param periodic Whether the map is periodic
+ */
+func mapBackend(noise, distance, metric string, periodic bool) (terrain.NoiseBackend, error) {
+	backend, ok := terrain.ParseNoiseBackend(noise)
+	if !ok {
+		return nil, fmt.Errorf("unknown noise backend %q", noise)
+	}
+	if err := checkPeriodic(periodic, noise); err != nil {
+		return nil, err
+	}
+	if noise == "worley" {
+		return mapWorley(distance, metric)
+	}
+	return backend, nil
+}
 
-	a, scene, cam := prepareScene(terrainMap.gradient_height_b1 / 2)
+/*
+ * Checks that a noise backend can be used by a periodic map. The lattice of simplex noise is skewed, so it never repeats
+ * across the bounds of a board.
+ * @param periodic Whether the map is periodic
+ * @param noise The name of the noise backend
+ */
+func checkPeriodic(periodic bool, noise string) error {
+	if periodic && noise == "simplex" {
+		return fmt.Errorf("simplex noise can not be periodic")
+	}
+	return nil
+}
 
-	terrain := new(BipartiteTerrain)
-	terrain.initialize(macro, micro, terrainWidth, terrainHeight, terrainMap.m, terrainMap.prop)
-	mat := material.NewStandard(math32.NewColor("darkgrey"))
-	mesh := graphic.NewMesh(terrain.geom, mat)
-	scene.Add(mesh)
+/*
+ * Checks that the octaves of a periodic fractal board line up into a tile. Octave i is sampled at lacunarity^i times the
+ * frequency of the board, so it only repeats with the board when lacunarity^i times the cells of the board is a whole number.
+ * @param periodic Whether the map is periodic
+ * @param gradientWidth The number of gradients in the X-direction of the board
+ * @param gradientHeight The number of gradients in the Y-direction of the board
+ * @param octaves The number of octaves of the board
+ * @param lacunarity The frequency multiplier between consecutive octaves
+ */
+func checkPeriodicFractal(periodic bool, gradientWidth, gradientHeight, octaves uint32, lacunarity float32) error {
+	if !periodic {
+		return nil
+	}
+	xBounds, yBounds := terrain.NewGradientBoard(gradientWidth, gradientHeight, 0).Bounds()
+	frequency := float64(1)
+	for i := uint32(1); i < octaves; i++ {
+		frequency *= float64(lacunarity)
+		if !wholeCells(float64(xBounds.Size())*frequency) || !wholeCells(float64(yBounds.Size())*frequency) {
+			return fmt.Errorf("%v does not tile, octave %d spans %v x %v cells of its noise, which need to be whole numbers",
+				lacunarity, i, float32(float64(xBounds.Size())*frequency), float32(float64(yBounds.Size())*frequency))
+		}
+	}
+	return nil
+}
 
-	completeScene(a, scene, terrain, cam)
+/*
+ * Checks that the domain warp of a periodic map lines up into a tile. The warp boards are sampled at warp_frequency times
+ * the coordinates of the terrain, so they only repeat with it when warp_frequency times its cells is a whole number.
+ * @param terrainMap The terrain map with the warp fields
+ * @param gradientWidth The number of gradients in the X-direction of the board the warp repeats with
+ * @param gradientHeight The number of gradients in the Y-direction of the board the warp repeats with
+ */
+func checkPeriodicWarp(terrainMap TerrainMap, gradientWidth, gradientHeight uint32) error {
+	if !terrainMap.periodic || terrainMap.warp_strength == 0 {
+		return nil
+	}
+	xBounds, yBounds := terrain.NewGradientBoard(gradientWidth, gradientHeight, 0).Bounds()
+	xCells := float64(xBounds.Size()) * float64(terrainMap.warp_frequency)
+	yCells := float64(yBounds.Size()) * float64(terrainMap.warp_frequency)
+	if !wholeCells(xCells) || !wholeCells(yCells) {
+		return fmt.Errorf("%v does not tile, the warp spans %v x %v cells of its noise, which need to be whole numbers",
+			terrainMap.warp_frequency, float32(xCells), float32(yCells))
+	}
+	return nil
 }
 
-// Make the terrain widths (passed as command line arguements) odd numbers
-// Some terrain sizes do not work because the gradients will not divide rationally into them(within the specificity of float32). It will be clear after running if the terrain/gradient sizes failed:
-//    - A diagonal section of the terrain will not be rendered
-// 	  and/or
-//    - An extra, large gray triangle will be rendered in the topside of the terrain.
-func main() {
-	if len(os.Args[1:]) == 3 {
-		var i interface{}
-		data, err1 := file.ReadFile(fmt.Sprintf("maps/%s.json", os.Args[1]))
-		if err1 != nil {
-			fmt.Println("Error! Could not read that file")
-			return
-		}
-		err2 := json.Unmarshal(data, &i)
-		if err2 != nil {
-			fmt.Println("Error! That json file could not be deconstructed into a terrain map")
-			return
-		}
+// Whether a number of cells is whole, allowing for the rounding of float32 map fields
+func wholeCells(cells float64) bool {
+	return math.Abs(cells-math.Round(cells)) < 1e-3
+}
 
-		terrainMap := TerrainMap{}
-		m := i.(map[string]interface{})
-		for k, v := range m {
-			switch k {
-			case "typ":
-				terrainMap.typ = uint8(v.(float64))
-			case "gradient_width_b1":
-				terrainMap.gradient_width_b1 = uint32(v.(float64))
-			case "gradient_height_b1":
-				terrainMap.gradient_height_b1 = uint32(v.(float64))
-			case "gradient_width_b2":
-				terrainMap.gradient_width_b2 = uint32(v.(float64))
-			case "gradient_height_b2":
-				terrainMap.gradient_height_b2 = uint32(v.(float64))
-			case "seed1":
-				terrainMap.seed1 = int32(v.(float64))
-			case "seed2":
-				terrainMap.seed2 = int32(v.(float64))
-			case "m":
-				terrainMap.m = float32(v.(float64))
-			case "prop":
-				terrainMap.prop = float32(v.(float64))
-			}
-		}
+/*
+ * Creates the domain warp of a terrain map, a map without a warp_strength gets a warp that leaves positions where they are
+ * @param terrainMap The terrain map with the warp fields
+ */
+func mapWarp(terrainMap TerrainMap) (terrain.DomainWarp, error) {
+	warp := terrain.NewDomainWarp(terrainMap.warp_seed, terrainMap.warp_strength, terrainMap.warp_frequency, terrainMap.warp_iterations)
+	backend, ok := terrain.ParseNoiseBackend(terrainMap.warp_noise)
+	if !ok {
+		return warp, fmt.Errorf("unknown warp noise backend %q", terrainMap.warp_noise)
+	}
+	if err := checkPeriodic(terrainMap.periodic, terrainMap.warp_noise); err != nil {
+		return warp, err
+	}
+	// The warp repeats with the first board of the map, or the first layer of a layered map
+	warpWidth, warpHeight := terrainMap.gradient_width_b1, terrainMap.gradient_height_b1
+	if terrainMap.typ == 4 && len(terrainMap.layers) > 0 {
+		warpWidth, warpHeight = terrainMap.layers[0].gradient_width, terrainMap.layers[0].gradient_height
+	}
+	if err := checkPeriodicWarp(terrainMap, warpWidth, warpHeight); err != nil {
+		return warp, fmt.Errorf("warp_frequency %v", err)
+	}
+	warp.UseBackend(backend)
+	return warp, nil
+}
 
-		terrainWidth, _ := strconv.ParseUint(os.Args[2], 10, 32)
-		terrainHeight, _ := strconv.ParseUint(os.Args[3], 10, 32)
+/*
+ * Creates a worley noise backend from the fields of a map file
+ * @param distance The name of the feature point distance returned by the noise
+ * @param metric The name of the metric the distances are measured with
+ */
+func mapWorley(distance, metric string) (terrain.WorleyBackend, error) {
+	worleyDistance, ok := terrain.ParseWorleyDistance(distance)
+	if !ok {
+		return terrain.WorleyBackend{}, fmt.Errorf("unknown worley distance %q", distance)
+	}
+	distanceMetric, ok := terrain.ParseDistanceMetric(metric)
+	if !ok {
+		return terrain.WorleyBackend{}, fmt.Errorf("unknown distance metric %q", metric)
+	}
+	return terrain.NewWorleyBackend(worleyDistance, distanceMetric), nil
+}
 
-		if terrainMap.typ == 1 {
-			renderSimpleTerrain(terrainMap, uint32(terrainWidth), uint32(terrainHeight))
-		} else if terrainMap.typ == 2 {
-			renderBipartiteTerrain(terrainMap, uint32(terrainWidth), uint32(terrainHeight))
-		} else {
-			fmt.Println("Had problems reading json or the type of map is not valid")
-		}
-	} else if len(os.Args[1:]) == 1 {
-		var i interface{}
-		data, err1 := file.ReadFile(fmt.Sprintf("%s.json", os.Args[1]))
-		if err1 != nil {
-			fmt.Println("Error! Could not read that file")
-			return
+/*
+ * Reads the heightmap of a layer, choosing its format by the extension of its path
+ * @param source Where the map of the layer was read from, the path of the heightmap is relative to it
+ * @param layerMap The terrain map layer of the heightmap
+ */
+func mapHeightmap(source mapSource, layerMap TerrainMapLayer) (terrain.Heightmap, error) {
+	f, err := source.open(layerMap.heightmap)
+	if err != nil {
+		return terrain.Heightmap{}, err
+	}
+	defer f.Close()
+
+	var field *terrain.HeightField
+	switch ext := strings.ToLower(filepath.Ext(layerMap.heightmap)); ext {
+	case ".png":
+		field, err = terrain.ReadHeightmapPNG(f)
+	case ".r16", ".r32":
+		bits := 16
+		if ext == ".r32" {
+			bits = 32
 		}
-		err2 := json.Unmarshal(data, &i)
-		if err2 != nil {
-			fmt.Println("Error! That json file could not be deconstructed into a terrain map")
-			return
+		var width, height uint32
+		if width, height, err = rawHeightmapSize(f, layerMap, bits); err != nil {
+			return terrain.Heightmap{}, err
 		}
+		field, err = terrain.ReadHeightmapRaw(f, bits, width, height)
+	default:
+		return terrain.Heightmap{}, fmt.Errorf("unknown heightmap format %q, heightmaps are png, r16 or r32", ext)
+	}
+	if err != nil {
+		return terrain.Heightmap{}, fmt.Errorf("could not read the heightmap %s: %v", layerMap.heightmap, err)
+	}
+	return terrain.NewHeightmap(field, layerMap.gradient_width, layerMap.gradient_height), nil
+}
 
-		terrainMap := TerrainMap{}
-		m := i.(map[string]interface{})
-		for k, v := range m {
-			switch k {
-			case "typ":
-				terrainMap.typ = uint8(v.(float64))
-			case "gradient_width_b1":
-				terrainMap.gradient_width_b1 = uint32(v.(float64))
-			case "gradient_height_b1":
-				terrainMap.gradient_height_b1 = uint32(v.(float64))
-			case "gradient_width_b2":
-				terrainMap.gradient_width_b2 = uint32(v.(float64))
-			case "gradient_height_b2":
-				terrainMap.gradient_height_b2 = uint32(v.(float64))
-			case "seed1":
-				terrainMap.seed1 = int32(v.(float64))
-			case "seed2":
-				terrainMap.seed2 = int32(v.(float64))
-			case "m":
-				terrainMap.m = float32(v.(float64))
-			case "prop":
-				terrainMap.prop = float32(v.(float64))
-			}
+/*
+ * Finds the width and height of a raw heightmap, from the heightmap_width and heightmap_height of its layer or as a square
+ * that fills the file. The size of the file is checked before the heightmap is read, so a mistyped size is reported
+ * instead of allocating a height field the file can not fill.
+ * @param f The raw heightmap
+ * @param layerMap The layer of the heightmap
+ * @param bits The size of a height, 16 or 32
+ */
+func rawHeightmapSize(f fs.File, layerMap TerrainMapLayer, bits int) (uint32, uint32, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	width, height := layerMap.heightmap_width, layerMap.heightmap_height
+	switch {
+	case width == 0 && height == 0:
+		width = uint32(math.Sqrt(float64(info.Size() / int64(bits/8))))
+		height = width
+		if int64(width)*int64(height)*int64(bits/8) != info.Size() {
+			return 0, 0, fmt.Errorf("the raw heightmap %s is not square, set its heightmap_width and heightmap_height", layerMap.heightmap)
 		}
+	case width == 0 || height == 0:
+		return 0, 0, fmt.Errorf("the raw heightmap %s needs both heightmap_width and heightmap_height, or neither for a square heightmap", layerMap.heightmap)
+	case int64(width)*int64(height)*int64(bits/8) != info.Size():
+		return 0, 0, fmt.Errorf("the raw heightmap %s is %d bytes, a %dx%d %d-bit heightmap is %d bytes",
+			layerMap.heightmap, info.Size(), width, height, bits, int64(width)*int64(height)*int64(bits/8))
+	}
+	return width, height, nil
+}
 
-		if terrainMap.typ == 1 {
-			renderSimpleTerrain(terrainMap, 124, 124)
-		} else if terrainMap.typ == 2 {
-			renderBipartiteTerrain(terrainMap, 124, 124)
-		} else {
-			fmt.Println("Had problems reading json or the type of map is not valid")
-		}
-	} else {
-		fmt.Println("Error! Need to pass in 1 or 3 command line arguements")
+/*
+ * Generates the terrain described by a terrain map with the builder of its typ
+ * @param terrainMap The terrain map to generate
+ * @param terrainWidth The number of vertices to be sampled in the x direction of the terrain
+ * @param terrainHeight The number of vertices to be sampled in the y direction of the terrain
+ */
+func buildTerrain(terrainMap TerrainMap, terrainWidth, terrainHeight uint32) (terrain.Terrain, error) {
+	switch terrainMap.typ {
+	case 1:
+		return buildSimpleTerrain(terrainMap, terrainWidth, terrainHeight)
+	case 2:
+		return buildBipartiteTerrain(terrainMap, terrainWidth, terrainHeight)
+	case 3:
+		return buildFractalTerrain(terrainMap, terrainWidth, terrainHeight)
+	case 4:
+		return buildLayeredTerrain(terrainMap, terrainWidth, terrainHeight)
 	}
+	return nil, fmt.Errorf("had problems reading json or the type of map is not valid")
 }
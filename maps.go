@@ -0,0 +1,231 @@
+package main
+
+import (
+	"embed"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// The maps in the maps directory are built into the program as presets, so it runs from anywhere
+//
+//go:embed maps/*
+var file embed.FS
+
+// The directories searched for map files, in order, before the presets, separated by the os path list separator
+var mapPath = defaultMapPath()
+
+/*
+ * Adds the -maps flag, which sets the search path of maps, to the flags of a command
+ * @param flags The flags of the command
+ */
+func addMapPathFlag(flags *flag.FlagSet) {
+	flags.StringVar(&mapPath, "maps", mapPath, "The directories searched for <mapname>.json, .yaml, .yml or .toml before the built-in presets, separated by "+string(os.PathListSeparator)+", $TERRAIN_MAP_PATH when it is set")
+}
+
+// The search path used when -maps is not passed, taken from the TERRAIN_MAP_PATH environment variable if it is set.
+// The current directory is not searched by default, since the json output files written there are not maps.
+func defaultMapPath() string {
+	if env := os.Getenv("TERRAIN_MAP_PATH"); env != "" {
+		return env
+	}
+	return "maps"
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=========================================mapSource==========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// Where a map was read from. The files a map names, like the heightmaps of its layers, are relative to its directory.
+type mapSource struct {
+	// The path of the map file, on the filesystem or in the embedded maps
+	path string
+	// Whether the map is one of the presets built into the program
+	preset bool
+}
+
+// Whether two sources are the same map file, however their paths were written
+func (source mapSource) same(other mapSource) bool {
+	if source.preset || other.preset {
+		return source == other
+	}
+	a, errA := filepath.Abs(source.path)
+	b, errB := filepath.Abs(other.path)
+	return errA == nil && errB == nil && a == b
+}
+
+/*
+ * Opens a file named by the map, relative to the directory of the map unless the name is an absolute path.
+ * The files named by a preset are read from the embedded maps.
+ * @param name The name of the file in the map
+ */
+func (source mapSource) open(name string) (fs.File, error) {
+	if source.preset {
+		return file.Open(path.Join(path.Dir(source.path), filepath.ToSlash(name)))
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(filepath.Dir(source.path), name)
+	}
+	return os.Open(name)
+}
+
+/*
+ * Finds a map by name and reads it. A name ending in the extension of a map format or containing a directory is the path
+ * of a map file. Any other name is looked up as <name>.json, .yaml, .yml and .toml in every directory of the search path,
+ * then in the built-in presets.
+ * @param name The name or path of the map
+ */
+func findTerrainMap(name string) (mapSource, []byte, error) {
+	if isMapPath(name) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return mapSource{}, nil, fmt.Errorf("could not read the map %s: %v", name, err)
+		}
+		return mapSource{path: name}, data, nil
+	}
+
+	dirs := filepath.SplitList(mapPath)
+	for _, dir := range dirs {
+		for _, f := range mapFormatExtensions {
+			p := filepath.Join(dir, name+f.ext)
+			data, err := os.ReadFile(p)
+			if err == nil {
+				return mapSource{path: p}, data, nil
+			}
+			if !os.IsNotExist(err) {
+				return mapSource{}, nil, fmt.Errorf("could not read the map %s: %v", p, err)
+			}
+		}
+	}
+
+	for _, f := range mapFormatExtensions {
+		p := fmt.Sprintf("maps/%s%s", name, f.ext)
+		if data, err := file.ReadFile(p); err == nil {
+			return mapSource{path: p, preset: true}, data, nil
+		}
+	}
+	return mapSource{}, nil, fmt.Errorf("there is no map named %s in %s or the built-in presets", name, strings.Join(dirs, ", "))
+}
+
+// Whether a map name is the path of a map file, it ends in the extension of a map format or contains a directory
+func isMapPath(name string) bool {
+	_, ok := mapFormatOf(name)
+	return ok || strings.ContainsRune(name, '/') || strings.ContainsRune(name, filepath.Separator)
+}
+
+/*
+ * Finds a map and parses its fields
+ * @param name The name or path of the map, see findTerrainMap
+ */
+func readTerrainMapFields(name string) (mapSource, map[string]interface{}, error) {
+	source, data, err := findTerrainMap(name)
+	if err != nil {
+		return mapSource{}, nil, err
+	}
+	m, err := parseTerrainMap(source.path, data)
+	return source, m, err
+}
+
+/*
+ * Finds, reads and validates a map, merged with the maps it extends
+ * @param name The name or path of the map, see findTerrainMap
+ */
+func readTerrainMap(name string) (TerrainMap, error) {
+	resolved, err := resolveTerrainMap(name)
+	if err != nil {
+		return TerrainMap{}, err
+	}
+	return decodeTerrainMap(resolved)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//========================================resolvedMap=========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// A map with the fields of the maps it extends merged into it. A map file can set extends to the name or path of
+// another map, and only list the fields it changes. The layers of a map are a single field, they replace the layers
+// of the map it extends instead of being merged with them.
+type resolvedMap struct {
+	// Where the map was read from
+	source mapSource
+	// The fields of the map and the maps it extends, without extends
+	fields map[string]interface{}
+	// Where each field was read from, which is one of the maps it extends for the fields it does not set itself
+	origins map[string]mapSource
+}
+
+/*
+ * Finds a map and merges it with the maps it extends, reporting a map that ends up extending itself
+ * @param name The name or path of the map, see findTerrainMap
+ */
+func resolveTerrainMap(name string) (resolvedMap, error) {
+	source, m, err := readTerrainMapFields(name)
+	if err != nil {
+		return resolvedMap{}, err
+	}
+	resolved := resolvedMap{source: source, fields: map[string]interface{}{}, origins: map[string]mapSource{}}
+	chain := []mapSource{source}
+	for {
+		for k, v := range m {
+			if _, ok := resolved.fields[k]; !ok && k != "extends" {
+				resolved.fields[k] = v
+				resolved.origins[k] = source
+			}
+		}
+		v, ok := m["extends"]
+		if !ok {
+			return resolved, nil
+		}
+		decoder := &mapDecoder{file: source.path}
+		parent := decoder.toString("extends", v)
+		if decoder.errs != nil {
+			return resolvedMap{}, decoder.errs
+		}
+
+		if source, m, err = readExtendedMap(source, parent); err != nil {
+			return resolvedMap{}, err
+		}
+		for _, s := range chain {
+			if s.same(source) {
+				paths := make([]string, len(chain)+1)
+				for i, c := range append(chain, source) {
+					paths[i] = c.path
+				}
+				decoder.fail("extends", "maps can not extend themselves, %s", strings.Join(paths, " extends "))
+				return resolvedMap{}, decoder.errs
+			}
+		}
+		chain = append(chain, source)
+	}
+}
+
+/*
+ * Finds and parses the map another map extends. A path is relative to the directory of the map that extends it, which
+ * is in the presets for a preset, and any other name is found like the name of a map on the command line.
+ * @param source Where the map that extends it was read from
+ * @param name The name or path of the extended map
+ */
+func readExtendedMap(source mapSource, name string) (mapSource, map[string]interface{}, error) {
+	if !isMapPath(name) {
+		return readTerrainMapFields(name)
+	}
+	var data []byte
+	var err error
+	if source.preset {
+		source = mapSource{path: path.Join(path.Dir(source.path), filepath.ToSlash(name)), preset: true}
+		data, err = file.ReadFile(source.path)
+	} else {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(source.path), name)
+		}
+		source = mapSource{path: name}
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return mapSource{}, nil, fmt.Errorf("could not read the map %s: %v", name, err)
+	}
+	m, err := parseTerrainMap(source.path, data)
+	return source, m, err
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/*
+ * Writes map files into a temporary directory
+ * @param t The test the maps are written for
+ * @param files The contents of the map files by their name
+ */
+func writeTestMaps(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// A map takes every field it does not set from the maps it extends, and its problems name the file they came from
+func TestResolveExtends(t *testing.T) {
+	dir := writeTestMaps(t, map[string]string{
+		"base.json":       `{"typ": 2, "gradient_width_b1": 5, "gradient_height_b1": 5, "gradient_width_b2": 27, "gradient_height_b2": 27, "seed1": 43, "seed2": 97, "m": 1.4, "prop": 0.91}`,
+		"sub/child.yaml":  "extends: ../base.json\nprop: 0.5\n",
+		"grandchild.toml": "extends = \"sub/child.yaml\"\nseed2 = 101\n",
+		"bad.json":        `{"extends": "base.json", "gradient_width_b1": 4}`,
+	})
+	resolved, err := resolveTerrainMap(filepath.Join(dir, "grandchild.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resolved.fields["extends"]; ok {
+		t.Error("the resolved map still extends another map")
+	}
+	for field, want := range map[string]interface{}{"prop": 0.5, "seed2": float64(101), "seed1": float64(43), "m": 1.4} {
+		if resolved.fields[field] != want {
+			t.Errorf("%s is %v, expected %v", field, resolved.fields[field], want)
+		}
+	}
+	if origin := resolved.origins["seed1"].path; origin != filepath.Join(dir, "base.json") {
+		t.Errorf("seed1 was read from %s, expected base.json", origin)
+	}
+
+	_, err = readTerrainMap(filepath.Join(dir, "bad.json"))
+	errs, ok := err.(ConfigErrors)
+	if !ok || len(errs) != 1 || errs[0].File != filepath.Join(dir, "bad.json") || errs[0].Field != "gradient_width_b1" {
+		t.Errorf("the even gradient width of bad.json is reported as %v", err)
+	}
+}
+
+// A map that ends up extending itself is reported with the maps it went through, however their paths are written
+func TestResolveExtendsCycle(t *testing.T) {
+	dir := writeTestMaps(t, map[string]string{
+		"self.json":  `{"extends": "self.json", "typ": 1}`,
+		"a.yaml":     "extends: sub/b.json\n",
+		"sub/b.json": `{"extends": "../c.toml"}`,
+		"c.toml":     "extends = \"./sub/../a.yaml\"\n",
+	})
+	for name, chain := range map[string]int{"self.json": 2, "a.yaml": 4} {
+		_, err := resolveTerrainMap(filepath.Join(dir, name))
+		if err == nil {
+			t.Fatalf("%s: a map that extends itself resolved", name)
+		}
+		if !strings.Contains(err.Error(), "can not extend themselves") || strings.Count(err.Error(), " extends ") != chain-1 {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+// A raw heightmap without dimensions is read as a square, and one whose size is not the square of a row is reported
+func TestMapHeightmapSquare(t *testing.T) {
+	dir := writeTestMaps(t, map[string]string{
+		"square.r16": string(make([]byte, 2*4*4)),
+		"wide.r32":   string(make([]byte, 4*3*2)),
+	})
+	source := mapSource{path: filepath.Join(dir, "map.json")}
+	layer := TerrainMapLayer{gradient_width: 3, gradient_height: 3, heightmap: "square.r16"}
+	if _, err := mapHeightmap(source, layer); err != nil {
+		t.Errorf("the 4x4 heightmap square.r16 could not be read: %v", err)
+	}
+
+	layer.heightmap = "wide.r32"
+	if _, err := mapHeightmap(source, layer); err == nil || !strings.Contains(err.Error(), "not square") {
+		t.Errorf("the 3x2 heightmap wide.r32 without dimensions is reported as %v", err)
+	}
+	layer.heightmap_width, layer.heightmap_height = 3, 2
+	if _, err := mapHeightmap(source, layer); err != nil {
+		t.Errorf("the 3x2 heightmap wide.r32 could not be read with its dimensions: %v", err)
+	}
+}
+
+// A raw heightmap whose dimensions do not match the size of its file, or that sets only one of them, is reported
+func TestMapHeightmapSize(t *testing.T) {
+	dir := writeTestMaps(t, map[string]string{"small.r16": string(make([]byte, 2*4*4))})
+	source := mapSource{path: filepath.Join(dir, "map.json")}
+	cases := []struct {
+		width, height uint32
+		message       string
+	}{
+		{4294967295, 4294967295, "is 32 bytes"},
+		{4, 5, "is 32 bytes"},
+		{4, 0, "needs both"},
+		{0, 4, "needs both"},
+	}
+	for _, c := range cases {
+		layer := TerrainMapLayer{gradient_width: 3, gradient_height: 3, heightmap: "small.r16", heightmap_width: c.width, heightmap_height: c.height}
+		if _, err := mapHeightmap(source, layer); err == nil || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("the %dx%d heightmap small.r16 is reported as %v", c.width, c.height, err)
+		}
+	}
+}
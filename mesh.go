@@ -0,0 +1,96 @@
+//go:build !headless
+
+package main
+
+import (
+	"terrain-generation/terrain"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//========================================TerrainMesh=========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// A terrain mesh is the g3n geometry of a terrain's height field. It places a vertex at every (column, row) of the field
+// at the height the field has for it, and keeps the geometry up to date as the terrain is moved. The vertices stay where
+// the field was when the mesh was built, so moving the terrain scrolls its heights through the mesh.
+type TerrainMesh struct {
+	// The surface geometry of the terrain
+	geom *geometry.Geometry
+	// The terrain whose height field is meshed
+	terrain terrain.Terrain
+}
+
+/*
+ * Creates the geometry of a terrain from the terrain's current height field
+ * @param surface The terrain to mesh
+ */
+func newTerrainMesh(surface terrain.Terrain) *TerrainMesh {
+	mesh := &TerrainMesh{geom: geometry.NewGeometry(), terrain: surface}
+	mesh.build()
+	return mesh
+}
+
+/*
+ * Fills the geometry with the vertices and triangles of the mesh of the terrain's height field, see terrain.NewMesh
+ */
+func (mesh *TerrainMesh) build() {
+	surface := terrain.NewMesh(mesh.terrain.HeightField())
+	positions := math32.NewArrayF32(0, surface.VertexCount()*6)
+	for i := 0; i < surface.VertexCount(); i++ {
+		positions.Append(surface.Positions[i*3 : i*3+3]...)
+		positions.Append(surface.Normals[i*3 : i*3+3]...)
+	}
+
+	mesh.geom.SetIndices(math32.ArrayU32(surface.Indices))
+	mesh.geom.AddVBO(gls.NewVBO(positions).
+		AddAttrib(gls.VertexPosition).
+		AddAttrib(gls.VertexNormal),
+	)
+}
+
+/*
+ * Copies the heights and normals of the mesh of the terrain's height field into the geometry after the terrain has moved.
+ * The normals are the ones terrain.NewMesh finds, so the geometry shades the same as the meshes written to output files.
+ */
+func (mesh *TerrainMesh) update() {
+	surface := terrain.NewMesh(mesh.terrain.HeightField())
+	i := 0
+	mesh.geom.OperateOnVertices(func(vertex *math32.Vector3) bool {
+		vertex.Z = surface.Positions[i*3+2]
+		i++
+		return false
+	})
+	i = 0
+	mesh.geom.OperateOnVertexNormals(func(normal *math32.Vector3) bool {
+		normal.Set(surface.Normals[i*3], surface.Normals[i*3+1], surface.Normals[i*3+2])
+		i++
+		return false
+	})
+}
+
+// Moves the terrain by amount vertices in the -x direction and updates the geometry
+func (mesh *TerrainMesh) MoveLeft(amount int) {
+	mesh.terrain.MoveLeft(amount)
+	mesh.update()
+}
+
+// Moves the terrain by amount vertices in the +x direction and updates the geometry
+func (mesh *TerrainMesh) MoveRight(amount int) {
+	mesh.terrain.MoveRight(amount)
+	mesh.update()
+}
+
+// Moves the terrain by amount vertices in the -y direction and updates the geometry
+func (mesh *TerrainMesh) MoveDown(amount int) {
+	mesh.terrain.MoveDown(amount)
+	mesh.update()
+}
+
+// Moves the terrain by amount vertices in the +y direction and updates the geometry
+func (mesh *TerrainMesh) MoveUp(amount int) {
+	mesh.terrain.MoveUp(amount)
+	mesh.update()
+}
@@ -0,0 +1,49 @@
+//go:build !headless
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"terrain-generation/terrain"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Moving a terrain mesh updates the normals of its geometry to the ones of the moved height field, still of unit length
+func TestTerrainMeshNormalsAfterMove(t *testing.T) {
+	surface := terrain.NewSimpleTerrain(terrain.NewGradientBoard(5, 5, 43), terrain.DomainWarp{}, 17, 13, 1.5)
+	mesh := newTerrainMesh(surface)
+	normals := func() []math32.Vector3 {
+		var normals []math32.Vector3
+		mesh.geom.OperateOnVertexNormals(func(normal *math32.Vector3) bool {
+			normals = append(normals, *normal)
+			return false
+		})
+		return normals
+	}
+	before := normals()
+	mesh.MoveRight(3)
+	after := normals()
+	want := terrain.NewMesh(surface.HeightField()).Normals
+
+	if len(after) != len(want)/3 {
+		t.Fatalf("the geometry has %d normals for %d vertices", len(after), len(want)/3)
+	}
+	changed := 0
+	for i, n := range after {
+		if n.X != want[i*3] || n.Y != want[i*3+1] || n.Z != want[i*3+2] {
+			t.Fatalf("vertex %d has the normal %v after moving, expected %v", i, n, want[i*3:i*3+3])
+		}
+		if math.Abs(float64(n.Length())-1) > 1e-5 {
+			t.Errorf("vertex %d has the normal %v of length %v", i, n, n.Length())
+		}
+		if n != before[i] {
+			changed++
+		}
+	}
+	if changed == 0 {
+		t.Error("the normals did not change when the terrain moved")
+	}
+}
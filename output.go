@@ -0,0 +1,228 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"terrain-generation/export"
+	"terrain-generation/terrain"
+)
+
+// The command line flags of the output files
+type outputFlags struct {
+	normalize    string
+	imageSize    string
+	xDisp        int
+	yDisp        int
+	colors       bool
+	chunk        uint
+	exaggeration float64
+	base         float64
+	region       string
+	cellSize     float64
+	origin       string
+	crs          uint
+	format       string
+}
+
+/*
+ * Adds the flags of the output files to the flags of a command
+ * @param flags The flags of the command
+ */
+func addOutputFlags(flags *flag.FlagSet) *outputFlags {
+	of := &outputFlags{}
+	flags.StringVar(&of.normalize, "normalize", "minmax", "How heights are mapped onto the gray levels of png heightmaps: minmax, or fixed to map -m to m")
+	flags.StringVar(&of.imageSize, "size", "", "The size of png heightmaps in pixels as <width>x<height>, by default the terrain's width and height")
+	flags.IntVar(&of.xDisp, "x", 0, "The number of vertices to move the terrain by in the x direction before writing it to the output files")
+	flags.IntVar(&of.yDisp, "y", 0, "The number of vertices to move the terrain by in the y direction before writing it to the output files")
+	flags.BoolVar(&of.colors, "colors", false, "Whether glb meshes have vertex colors from their heights")
+	flags.UintVar(&of.chunk, "chunk", 0, "The number of vertices along each side of the chunks glb meshes are split into, 0 for a single chunk")
+	flags.Float64Var(&of.exaggeration, "exaggeration", 1, "The multiplier of the heights of stl models")
+	flags.Float64Var(&of.base, "base", 1, "The distance from the lowest point of an stl model down to the bottom of its base plate")
+	flags.StringVar(&of.region, "region", "", "The vertices written to stl models as <col>,<row>,<width>x<height>, by default the whole terrain")
+	flags.Float64Var(&of.cellSize, "cellsize", 0, "The size of a cell of asc, r32 and tif elevation grids, 0 for the spacing of the terrain's vertices")
+	flags.StringVar(&of.origin, "origin", "0,0", "Added to the position of the lower left cell of asc, r32 and tif elevation grids, as <x>,<y>")
+	flags.UintVar(&of.crs, "crs", 0, "The EPSG code of the coordinate reference system written to tif elevation grids, 0 to leave it unknown")
+	flags.StringVar(&of.format, "format", "", "The format of the output files: "+strings.Join(outputFormats(), ", ")+", by default the extension of each file")
+	return of
+}
+
+// The options of the output files a terrain is written to
+type OutputOptions struct {
+	// The options of png heightmaps
+	png export.PNGOptions
+	// The options of glb meshes
+	glb export.GLBOptions
+	// The options of stl models
+	stl export.STLOptions
+	// The options of asc, r32 and tif elevation grids
+	dem export.DEMOptions
+	// The format every output file is written in, or empty to choose the format of each file by its extension
+	format string
+}
+
+// Writes a height field in the format of an output file, formats that are written with other files next to them use its path
+type outputWriter func(path string, w io.Writer, field *terrain.HeightField, options OutputOptions) error
+
+// The writers of the output files a terrain can be written to, by file extension
+var outputWriters = map[string]outputWriter{
+	".json": func(path string, w io.Writer, field *terrain.HeightField, options OutputOptions) error {
+		return export.WriteJSON(w, field)
+	},
+	".png": func(path string, w io.Writer, field *terrain.HeightField, options OutputOptions) error {
+		return export.WritePNG(w, field, options.png)
+	},
+	".obj": writeOBJ,
+	".glb": func(path string, w io.Writer, field *terrain.HeightField, options OutputOptions) error {
+		return export.WriteGLB(w, field, options.glb)
+	},
+	".stl": func(path string, w io.Writer, field *terrain.HeightField, options OutputOptions) error {
+		return export.WriteSTL(w, field, options.stl)
+	},
+	".asc": func(path string, w io.Writer, field *terrain.HeightField, options OutputOptions) error {
+		return export.WriteASC(w, field, options.dem)
+	},
+	".r32":  writeR32,
+	".tif":  writeGeoTIFF,
+	".tiff": writeGeoTIFF,
+}
+
+/*
+ * Writes the mesh of a height field as an obj, together with an mtl of the same name next to it
+ * @param path The path of the obj
+ * @param w The writer of the obj
+ * @param field The height field to write
+ * @param options The options of the output files
+ */
+func writeOBJ(path string, w io.Writer, field *terrain.HeightField, options OutputOptions) error {
+	materialLib := strings.TrimSuffix(path, filepath.Ext(path)) + ".mtl"
+	writeMTL := func(path string, w io.Writer, field *terrain.HeightField, options OutputOptions) error {
+		return export.WriteMTL(w)
+	}
+	if err := writeOutput(materialLib, field, options, writeMTL); err != nil {
+		return err
+	}
+	return export.WriteOBJ(w, field, filepath.Base(materialLib))
+}
+
+/*
+ * Writes the heights of a height field as raw float32, together with an hdr header of the same name next to them
+ * @param path The path of the heights
+ * @param w The writer of the heights
+ * @param field The height field to write
+ * @param options The options of the output files
+ */
+func writeR32(path string, w io.Writer, field *terrain.HeightField, options OutputOptions) error {
+	writeHeader := func(path string, w io.Writer, field *terrain.HeightField, options OutputOptions) error {
+		return export.WriteR32Header(w, field, options.dem)
+	}
+	if err := writeOutput(strings.TrimSuffix(path, filepath.Ext(path))+".hdr", field, options, writeHeader); err != nil {
+		return err
+	}
+	return export.WriteR32(w, field)
+}
+
+// Writes a height field as a geotiff
+func writeGeoTIFF(path string, w io.Writer, field *terrain.HeightField, options OutputOptions) error {
+	return export.WriteGeoTIFF(w, field, options.dem)
+}
+
+/*
+ * Creates the options of the output files from the command line flags
+ * @param terrainMap The terrain map of the terrain being written, its magnitude is the fixed range of png heightmaps
+ */
+func (of *outputFlags) options(terrainMap TerrainMap) (OutputOptions, error) {
+	var options OutputOptions
+	normalization, ok := export.ParseNormalization(of.normalize)
+	if !ok {
+		return options, fmt.Errorf("unknown normalization %q", of.normalize)
+	}
+	options.png = export.PNGOptions{Normalization: normalization, Low: -float64(terrainMap.m), High: float64(terrainMap.m)}
+	options.glb = export.GLBOptions{VertexColors: of.colors, ChunkSize: uint32(of.chunk)}
+	options.stl = export.STLOptions{Exaggeration: of.exaggeration, BaseThickness: of.base}
+	if of.region != "" {
+		stl := &options.stl
+		if _, err := fmt.Sscanf(of.region, "%d,%d,%dx%d", &stl.Col, &stl.Row, &stl.Width, &stl.Height); err != nil {
+			return options, fmt.Errorf("the region %q is not <col>,<row>,<width>x<height>", of.region)
+		}
+	}
+	if of.crs > math.MaxUint16 {
+		return options, fmt.Errorf("the crs %d is not an EPSG code", of.crs)
+	}
+	options.dem = export.DEMOptions{CellSize: of.cellSize, CRS: uint16(of.crs)}
+	if _, err := fmt.Sscanf(of.origin, "%g,%g", &options.dem.OriginX, &options.dem.OriginY); err != nil {
+		return options, fmt.Errorf("the origin %q is not <x>,<y>", of.origin)
+	}
+	if of.imageSize != "" {
+		if _, err := fmt.Sscanf(of.imageSize, "%dx%d", &options.png.Width, &options.png.Height); err != nil {
+			return options, fmt.Errorf("the size %q is not <width>x<height>", of.imageSize)
+		}
+	}
+	if of.format != "" {
+		options.format = "." + strings.TrimPrefix(strings.ToLower(of.format), ".")
+		if _, ok := outputWriters[options.format]; !ok {
+			return options, fmt.Errorf("unknown output format %q, expected %s", of.format, strings.Join(outputFormats(), ", "))
+		}
+	}
+	return options, nil
+}
+
+// The names of the formats output files can be written in, without the dot of their extension
+func outputFormats() []string {
+	formats := make([]string, 0, len(outputWriters))
+	for ext := range outputWriters {
+		formats = append(formats, strings.TrimPrefix(ext, "."))
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+/*
+ * Writes a height field to every output file, choosing the format of each file by its extension unless the options
+ * choose a format for all of them
+ * @param field The height field to write
+ * @param outputs The paths of the output files
+ * @param options The options of the output files
+ */
+func writeOutputs(field *terrain.HeightField, outputs []string, options OutputOptions) error {
+	for _, output := range outputs {
+		format := options.format
+		if format == "" {
+			format = strings.ToLower(filepath.Ext(output))
+		}
+		write, ok := outputWriters[format]
+		if !ok {
+			return fmt.Errorf("can not write %s, unknown output format %q", output, format)
+		}
+		if err := writeOutput(output, field, options, write); err != nil {
+			return err
+		}
+		fmt.Println("Wrote", output)
+	}
+	return nil
+}
+
+/*
+ * Creates an output file and writes a height field to it
+ * @param path The path of the output file
+ * @param field The height field to write
+ * @param options The options of the output files
+ * @param write The writer of the file's format
+ */
+func writeOutput(path string, field *terrain.HeightField, options OutputOptions, write outputWriter) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(path, f, field, options); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write %s: %v", path, err)
+	}
+	return f.Close()
+}
@@ -1 +1,5 @@
-go run generate.go main.go $1 $2 $3 > out.txt
+# The viewer logs to out.txt, the subcommands print to the terminal
+case "$1" in
+export|info|validate|convert|resolve|stats|batch|help) go run . "$@" ;;
+*) go run . "$@" > out.txt ;;
+esac
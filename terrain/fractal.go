@@ -0,0 +1,275 @@
+package terrain
+
+import (
+	"fmt"
+	"math"
+)
+
+// The most octaves a fractal board sums. Every octave doubles the detail of the one before it at the default lacunarity,
+// so octaves past this are far finer than any terrain can sample.
+const MaxOctaves = 16
+
+/*
+ * Checks the number of octaves of a fractal board, which has to be from 1 to MaxOctaves. NewFractalBoard clamps octaves
+ * that do not pass, so map files are checked with it first to report them instead.
+ * @param octaves The number of octaves
+ */
+func CheckOctaves(octaves uint32) error {
+	if octaves < 1 || octaves > MaxOctaves {
+		return fmt.Errorf("%d is outside of [1, %d]", octaves, MaxOctaves)
+	}
+	return nil
+}
+
+/*
+ * Checks a lacunarity or persistence of a fractal board, which has to be above 0. NewFractalBoard replaces multipliers
+ * that do not pass with their defaults, so map files are checked with it first to report them instead.
+ * @param multiplier The lacunarity or persistence
+ */
+func CheckMultiplier(multiplier float32) error {
+	// A negated comparison so NaN does not pass either
+	if !(multiplier > 0) {
+		return fmt.Errorf("%v is not above 0", multiplier)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//========================================FractalStyle========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The way a FractalBoard transforms and sums the noise of its octaves
+type FractalStyle uint8
+
+const (
+	// Fractal brownian motion, the noise of the octaves is summed as is
+	FractalFBM FractalStyle = iota
+	// Ridged multifractal, the absolute noise is inverted into sharp ridges and each octave is weighted by the one before it
+	// so the detail gathers on the ridges and the valleys stay smooth
+	FractalRidged
+	// Billow, the absolute noise is summed so the octaves form rounded, puffy hills
+	FractalBillow
+)
+
+// The fractal styles as they are written in map files
+var fractalNames = map[string]FractalStyle{
+	"fbm":    FractalFBM,
+	"ridged": FractalRidged,
+	"billow": FractalBillow,
+}
+
+/*
+ * Finds the fractal style with a name from a map file
+ * @param name The name of the fractal style
+ */
+func ParseFractalStyle(name string) (FractalStyle, bool) {
+	style, ok := fractalNames[name]
+	return style, ok
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//========================================FractalBoard========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// A fractal board sums the noise of several gradient boards (octaves) into fractal brownian motion (fBm), or into one of
+// its ridged and billow variants. Every octave samples at lacunarity times the frequency and persistence times the
+// amplitude of the one before it.
+type FractalBoard struct {
+	// The gradient boards of each octave, from the coarsest to the finest
+	octaves []GradientBoard
+	// The frequency multiplier between consecutive octaves
+	lacunarity float32
+	// The amplitude multiplier between consecutive octaves
+	persistence float32
+	// The way the noise of the octaves is transformed and summed
+	style FractalStyle
+	// The height of the ridges of a ridged board before they are sharpened
+	offset float32
+	// How strongly each octave of a ridged board weights the detail of the next octave
+	gain float32
+	// The exponent that narrows the ridges of a ridged board
+	sharpness float32
+}
+
+/*
+ * Derives the seed of an octave from the seed of the fractal board. Each octave gets its own well-distributed seed so that
+ * the octaves do not repeat the same gradients at different scales. The first octave keeps the board's seed.
+ * @param seed The seed of the fractal board
+ * @param octave The index of the octave, 0 being the coarsest
+ */
+func octaveSeed(seed int32, octave int) int32 {
+	if octave == 0 {
+		return seed
+	}
+	su := mix(uint32(seed) ^ (uint32(octave) * 0x9e3779b9))
+	// An even seed loses the low bits of every hashed coordinate, an odd seed never does
+	return int32(su | 1)
+}
+
+/*
+ * Creates a fractal brownian motion board with a gradient board for each of its octaves. The octaves are clamped to
+ * [1, MaxOctaves], and a lacunarity or persistence that is not above 0 is replaced by its default, 2 or 0.5, so the
+ * board never allocates without bound or sums its octaves into NaN.
+ * @param gradientWidth The number of gradients in the X-direction of the coarsest octave centered about the origin
+ * @param gradientHeight The number of gradients in the Y-direction of the coarsest octave centered about the origin
+ * @param seed The seed that the octave seeds are derived from
+ * @param octaves The number of octaves summed by the board
+ * @param lacunarity The frequency multiplier between consecutive octaves
+ * @param persistence The amplitude multiplier between consecutive octaves
+ */
+func NewFractalBoard(gradientWidth, gradientHeight uint32, seed int32, octaves uint32, lacunarity, persistence float32) FractalBoard {
+	if octaves < 1 {
+		octaves = 1
+	} else if CheckOctaves(octaves) != nil {
+		octaves = MaxOctaves
+	}
+	if CheckMultiplier(lacunarity) != nil {
+		lacunarity = 2
+	}
+	if CheckMultiplier(persistence) != nil {
+		persistence = 0.5
+	}
+	fractal := FractalBoard{
+		octaves:     make([]GradientBoard, octaves),
+		lacunarity:  lacunarity,
+		persistence: persistence,
+		style:       FractalFBM,
+		offset:      1,
+		gain:        2,
+		sharpness:   2,
+	}
+	for i := range fractal.octaves {
+		fractal.octaves[i] = NewGradientBoard(gradientWidth, gradientHeight, octaveSeed(seed, i))
+	}
+	return fractal
+}
+
+/*
+ * Changes the way the board transforms and sums the noise of its octaves, boards use FractalFBM unless they are changed
+ * @param style The fractal style of the board
+ * @param offset The height of the ridges of a ridged board before they are sharpened
+ * @param gain How strongly each octave of a ridged board weights the detail of the next octave
+ * @param sharpness The exponent that narrows the ridges of a ridged board
+ */
+func (fractal *FractalBoard) UseStyle(style FractalStyle, offset, gain, sharpness float32) {
+	fractal.style = style
+	fractal.offset = offset
+	fractal.gain = gain
+	fractal.sharpness = sharpness
+}
+
+// The bounds of the coarsest octave of the board, the coordinates Noise is sampled in
+func (fractal FractalBoard) Bounds() (Bounds, Bounds) {
+	if len(fractal.octaves) == 0 {
+		return Bounds{}, Bounds{}
+	}
+	return fractal.octaves[0].xBounds, fractal.octaves[0].yBounds
+}
+
+/*
+ * Makes every octave of the board repeat when the coarsest octave spans its bounds. Each octave is sampled at a higher
+ * frequency, so its period is lacunarity^octave times the period of the coarsest octave, and the octaves only line up
+ * into a seamless tile when that is a whole number of cells, i.e. when the lacunarity is a whole number.
+ */
+func (fractal *FractalBoard) Tile() {
+	frequency := float64(1)
+	for i := range fractal.octaves {
+		board := &fractal.octaves[i]
+		board.UsePeriod(uint32(math.Round(float64(board.xBounds.Size())*frequency)), uint32(math.Round(float64(board.yBounds.Size())*frequency)))
+		frequency *= float64(fractal.lacunarity)
+	}
+}
+
+/*
+ * Changes the gradient noise algorithm evaluated by every octave of the board
+ * @param backend The noise backend the octaves will evaluate
+ */
+func (fractal *FractalBoard) UseBackend(backend NoiseBackend) {
+	for i := range fractal.octaves {
+		fractal.octaves[i].UseBackend(backend)
+	}
+}
+
+/*
+ * Fractal brownian motion will sum the noise of each octave and normalize it by the total amplitude of the octaves,
+ * so the result stays within the range of a single octave of noise. Ridged boards return heights from 0 up to
+ * offset^sharpness, and billow boards return heights from 0 up to the largest absolute noise of a single octave.
+ * @param x The x position at which we would like to have a height, in the coordinates of the coarsest octave
+ * @param y The y position at which we would like to have a height, in the coordinates of the coarsest octave
+ */
+func (fractal FractalBoard) Noise(x, y float32) float32 {
+	sum := float32(0)
+	total := float32(0)
+	frequency := float32(1)
+	amplitude := float32(1)
+	// The weight of the next octave of a ridged board, taken from the signal of the octave before it
+	weight := float32(1)
+	for _, board := range fractal.octaves {
+		signal := board.Noise(x*frequency, y*frequency)
+		switch fractal.style {
+		case FractalRidged:
+			signal = fractal.offset - abs(signal)
+			if signal < 0 {
+				signal = 0
+			}
+			signal = float32(math.Pow(float64(signal), float64(fractal.sharpness))) * weight
+			weight = signal * fractal.gain
+			if weight < 0 {
+				weight = 0
+			} else if weight > 1 {
+				weight = 1
+			}
+		case FractalBillow:
+			signal = abs(signal)
+		}
+		sum += signal * amplitude
+		total += amplitude
+		frequency *= fractal.lacunarity
+		amplitude *= fractal.persistence
+	}
+	if total == 0 || math.IsInf(float64(total), 0) {
+		return 0
+	}
+	return sum / total
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////////
+//==========================================FractalTerrain===========================================//
+///////////////////////////////////////////////////////////////////////////////////////////////////////
+type FractalTerrain struct {
+	// The height field, grid and displacement of this terrain, the grid is sampled from the coarsest octave
+	terrainBase
+	// The fractal board of this terrain used by the fractal brownian motion generation
+	fractal FractalBoard
+	// The distortion applied to the coordinates the fractal board is sampled at
+	warp DomainWarp
+	// The magnitude of this terrain
+	m float32
+}
+
+/*
+ * Creates a fractal terrain and generates its heights
+ * @param fractal The fractal board used to generate the fractal brownian motion textures
+ * @param warp The distortion applied to the coordinates the fractal board is sampled at
+ * @param terrainWidth The number of vertices to be sampled in the x direction of the terrain
+ * @param terrainHeight The number of vertices to be sampled in the y direction of the terrain
+ * @param m The magnitude of the terrain
+ */
+func NewFractalTerrain(fractal FractalBoard, warp DomainWarp, terrainWidth, terrainHeight uint32, m float32) *FractalTerrain {
+	xBounds, yBounds := fractal.Bounds()
+	terrain := &FractalTerrain{
+		fractal: fractal,
+		warp:    warp,
+		m:       m,
+	}
+	terrain.init(NewSamplingGrid(xBounds, yBounds, terrainWidth, terrainHeight), terrainWidth, terrainHeight, terrain.HeightAt)
+	return terrain
+}
+
+/*
+ * Calculates the height of the terrain at a column and row of its grid, taking the current displacement into account
+ * @param col The column of the vertex in the grid
+ * @param row The row of the vertex in the grid
+ */
+func (terrain *FractalTerrain) HeightAt(col, row int) float32 {
+	x, y := terrain.warp.Apply(terrain.grid.X(col+terrain.xDisp), terrain.grid.Y(row+terrain.yDisp))
+	return terrain.fractal.Noise(x, y) * terrain.m
+}
@@ -0,0 +1,109 @@
+package terrain
+
+import (
+	"math"
+	"testing"
+)
+
+// Fractal boards clamp their octaves and replace multipliers that would make their noise NaN
+func TestNewFractalBoardGuards(t *testing.T) {
+	cases := []struct {
+		octaves                 uint32
+		lacunarity, persistence float32
+		boards                  int
+	}{
+		{0, 2, 0.5, 1},
+		{1000000000, 2, 0.5, MaxOctaves},
+		{2, 0, -1, 2},
+		{3, float32(math.NaN()), float32(math.NaN()), 3},
+	}
+	for _, c := range cases {
+		fractal := NewFractalBoard(5, 5, 43, c.octaves, c.lacunarity, c.persistence)
+		if len(fractal.octaves) != c.boards {
+			t.Errorf("%d octaves made %d boards, expected %d", c.octaves, len(fractal.octaves), c.boards)
+		}
+		if h := fractal.Noise(0.3, -1.7); math.IsNaN(float64(h)) || math.IsInf(float64(h), 0) {
+			t.Errorf("%d octaves, lacunarity %v, persistence %v: noise is %v", c.octaves, c.lacunarity, c.persistence, h)
+		}
+	}
+}
+
+/*
+ * Samples a fractal board on a grid of positions over its bounds, at a quarter of a gradient cell apart
+ * @param fractal The fractal board to sample
+ */
+func sampleFractal(fractal FractalBoard) []float32 {
+	xBounds, yBounds := fractal.Bounds()
+	var heights []float32
+	for y := float32(yBounds.Lower()); y <= float32(yBounds.Upper()); y += 0.25 {
+		for x := float32(xBounds.Lower()); x <= float32(xBounds.Upper()); x += 0.25 {
+			heights = append(heights, fractal.Noise(x, y))
+		}
+	}
+	return heights
+}
+
+// Billow noise is the absolute noise of its octaves, so it is never below 0
+func TestBillowNotNegative(t *testing.T) {
+	for _, octaves := range []uint32{1, 4} {
+		fbm := NewFractalBoard(5, 5, 43, octaves, 2, 0.5)
+		billow := fbm
+		billow.UseStyle(FractalBillow, 1, 2, 2)
+		base, heights := sampleFractal(fbm), sampleFractal(billow)
+		for i, h := range heights {
+			if h < 0 {
+				t.Fatalf("%d octaves: billow noise is %v at sample %d", octaves, h, i)
+			}
+			if octaves == 1 && h != abs(base[i]) {
+				t.Fatalf("one octave of billow noise is %v where the noise is %v", h, base[i])
+			}
+		}
+	}
+}
+
+// Ridged noise peaks at offset^sharpness where the noise of its octave is 0, which gradient noise is on every gradient
+func TestRidgedPeaksAtZeroNoise(t *testing.T) {
+	fbm := NewFractalBoard(5, 5, 43, 1, 2, 0.5)
+	ridged := fbm
+	ridged.UseStyle(FractalRidged, 1.2, 2, 3)
+	peak := float32(math.Pow(1.2, 3))
+	xBounds, yBounds := fbm.Bounds()
+	for y := yBounds.Lower(); y <= yBounds.Upper(); y++ {
+		for x := xBounds.Lower(); x <= xBounds.Upper(); x++ {
+			if n := fbm.Noise(float32(x), float32(y)); n != 0 {
+				t.Fatalf("the noise on the gradient (%d, %d) is %v", x, y, n)
+			}
+			if h := ridged.Noise(float32(x), float32(y)); math.Abs(float64(h-peak)) > 1e-5 {
+				t.Errorf("ridged noise on the gradient (%d, %d) is %v, expected the peak %v", x, y, h, peak)
+			}
+		}
+	}
+	for i, h := range sampleFractal(ridged) {
+		if h < 0 || h > peak+1e-5 {
+			t.Fatalf("ridged noise is %v at sample %d, outside of [0, %v]", h, i, peak)
+		}
+	}
+}
+
+// The offset, gain and sharpness of a ridged board each change its noise
+func TestRidgedParameters(t *testing.T) {
+	styled := func(offset, gain, sharpness float32) []float32 {
+		fractal := NewFractalBoard(5, 5, 43, 4, 2, 0.5)
+		fractal.UseStyle(FractalRidged, offset, gain, sharpness)
+		return sampleFractal(fractal)
+	}
+	base := styled(1, 2, 2)
+	variants := map[string][]float32{"offset": styled(0.8, 2, 2), "gain": styled(1, 0.5, 2), "sharpness": styled(1, 2, 1)}
+	for name, heights := range variants {
+		changed := 0
+		for i := range heights {
+			if heights[i] != base[i] {
+				changed++
+			}
+		}
+		// Every sample away from the peaks changes, so more than half of them do
+		if changed < len(base)/2 {
+			t.Errorf("changing the %s changed %d of %d samples", name, changed, len(base))
+		}
+	}
+}
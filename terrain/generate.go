@@ -0,0 +1,480 @@
+// Package terrain generates procedural terrain from gradient noise. Terrains sample their gradient boards on a grid of
+// vertices into a HeightField, which can be rendered, exported or analysed without depending on any graphics library.
+package terrain
+
+import (
+	"math"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//===========================================Bounds===========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The lower and upper gradient coordinates a board spans in one direction
+type Bounds struct {
+	lower, upper int32
+}
+
+// The lowest gradient coordinate of the bounds
+func (bounds *Bounds) Lower() int32 {
+	return bounds.lower
+}
+
+// The highest gradient coordinate of the bounds
+func (bounds *Bounds) Upper() int32 {
+	return bounds.upper
+}
+
+// The number of gradient cells spanned by the bounds
+func (bounds *Bounds) Size() int {
+	return int(math.Abs(float64(bounds.upper) - float64(bounds.lower)))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////
+//=======================================GradientBoard=======================================//
+///////////////////////////////////////////////////////////////////////////////////////////////
+type GradientBoard struct {
+	xBounds Bounds
+	yBounds Bounds
+	seed    int32
+	// The gradient noise algorithm evaluated by the board
+	backend NoiseBackend
+	// The number of cells after which the gradients repeat in the x and y directions, 0 for a board that never repeats
+	xPeriod int32
+	yPeriod int32
+}
+
+/*
+ * Hash is a GradientBoard method that will take in integer coordinate points and hash them with the board's seed, after
+ * wrapping them into the board's period. Every value the board derives from its lattice points comes from this hash.
+ * This function is deterministic and well-distributed.
+ * @param x the x coordinate to hash
+ * @param y the y coordinate to hash
+ */
+func (board GradientBoard) hash(x, y int32) uint {
+	x, y = board.wrap(x, y)
+	xu := mix(uint(x * board.seed))
+	yu := mix(uint(y * board.seed))
+	return 31*(31+xu) + yu
+}
+
+/*
+ * Mixes the bits of a value so that values differing in any bit are spread over the whole range. It is the one mixer of
+ * the package, used by the lattice hash of the boards and to derive seeds, so they all hash in the same style.
+ * @param v The value to mix
+ */
+func mix[T uint | uint32](v T) T {
+	v = ((v >> 16) ^ v) * 0x45d9f3b
+	v = ((v >> 16) ^ v) * 0x45d9f3b
+	return (v >> 16) ^ v
+}
+
+/*
+ * Calculate gradient is a GradientBoard method that will take in integer coordinate points and determine the gradient angle of the board at that location.
+ * It produces a gradient angle from the hash of the coordinates.
+ * @param x the x coordinate to calculate the gradient for
+ * @param y the y coordinate to calculate the gradient for
+ */
+func (board GradientBoard) calculateGradient(x, y int32) uint16 {
+	return uint16(board.hash(x, y) % 360)
+}
+
+/*
+ * Creates a gradient board with its bounds about the origin (0, 0) that evaluates perlin noise
+ * @param gradientWidth The number of gradients in the X-direction that the Board has centered about the origin
+ * @param gradientHeight The number of gradients in the Y-direction that the Board has centered about the origin
+ * @seed seed The seed that the board will use to generate it's gradients.
+ *
+ */
+func NewGradientBoard(gradientWidth, gradientHeight uint32, seed int32) GradientBoard {
+	return GradientBoard{
+		xBounds: Bounds{-int32(gradientWidth) / 2, int32(gradientWidth) / 2},
+		yBounds: Bounds{-int32(gradientHeight) / 2, int32(gradientHeight) / 2},
+		seed:    seed,
+		backend: PerlinBackend{},
+	}
+}
+
+// The bounds of the board in the x and y directions, the coordinates a terrain samples it in
+func (board GradientBoard) Bounds() (Bounds, Bounds) {
+	return board.xBounds, board.yBounds
+}
+
+/*
+ * Makes the gradients of the board repeat every xPeriod cells in the x direction and every yPeriod cells in the y direction,
+ * so the noise of the board is periodic and a heightmap spanning a whole number of periods tiles seamlessly
+ * @param xPeriod The number of cells after which the gradients repeat in the x direction, 0 to never repeat
+ * @param yPeriod The number of cells after which the gradients repeat in the y direction, 0 to never repeat
+ */
+func (board *GradientBoard) UsePeriod(xPeriod, yPeriod uint32) {
+	board.xPeriod = int32(xPeriod)
+	board.yPeriod = int32(yPeriod)
+}
+
+// Makes the gradients of the board repeat every time they span the board's bounds, so a terrain covering them tiles
+func (board *GradientBoard) Tile() {
+	board.UsePeriod(uint32(board.xBounds.Size()), uint32(board.yBounds.Size()))
+}
+
+/*
+ * Wraps integer coordinate points of the board into its period, coordinates of a board without a period are returned as is
+ * @param x the x coordinate to wrap
+ * @param y the y coordinate to wrap
+ */
+func (board GradientBoard) wrap(x, y int32) (int32, int32) {
+	if board.xPeriod > 0 {
+		x = ((x % board.xPeriod) + board.xPeriod) % board.xPeriod
+	}
+	if board.yPeriod > 0 {
+		y = ((y % board.yPeriod) + board.yPeriod) % board.yPeriod
+	}
+	return x, y
+}
+
+/*
+ * Changes the gradient noise algorithm evaluated by the board, boards use the PerlinBackend unless they are changed
+ * @param backend The noise backend the board will evaluate
+ */
+func (board *GradientBoard) UseBackend(backend NoiseBackend) {
+	board.backend = backend
+}
+
+/*
+ * Evaluates the noise backend of the board, returning some float32 height given some board at an x and y position
+ * @param x The x position at which we would like to have a height
+ * @param y The y position at which we would like to have a height
+ */
+func (board GradientBoard) Noise(x, y float32) float32 {
+	h, _, _ := board.backend.Evaluate(board, x, y)
+	return h
+}
+
+/*
+ * Evaluates the noise backend of the board, returning the height at an x and y position and its partial derivatives (dh/dx, dh/dy)
+ * @param x The x position at which we would like to have a height
+ * @param y The y position at which we would like to have a height
+ */
+func (board GradientBoard) NoiseDerivatives(x, y float32) (float32, float32, float32) {
+	return board.backend.Evaluate(board, x, y)
+}
+
+/*
+ * Perlin noise algorithm will return some float32 (-1.5, 1.5) given some board at an x and y position
+ * @param x The x position at which we would like to have a height (Note: x must be within board's xBounds)
+ * @param y The y position at which we would like to have a height (Note: y must be within board's yBounds)
+ */
+func (board GradientBoard) perlinNoise(x, y float32) float32 {
+	nxy, _, _ := board.perlinNoiseDerivatives(x, y)
+	return nxy
+}
+
+/*
+ * Evaluates the perlin noise algorithm at an x and y position and returns the height together with its exact partial
+ * derivatives (dh/dx, dh/dy). The height is the same as the one returned by perlinNoise, and the derivatives follow from
+ * differentiating the quintic fade and the dot products of the four surrounding gradients.
+ * @param x The x position at which we would like to have a height (Note: x must be within board's xBounds)
+ * @param y The y position at which we would like to have a height (Note: y must be within board's yBounds)
+ */
+func (board GradientBoard) perlinNoiseDerivatives(x, y float32) (float32, float32, float32) {
+	x0 := int32(math.Floor(float64(x))) - board.xBounds.lower
+	x1 := x0 + 1
+	y0 := int32(math.Floor(float64(y))) - board.yBounds.lower
+	y1 := y0 + 1
+
+	sx := (x - float32(board.xBounds.lower)) - float32(x0)
+	sy := (y - float32(board.yBounds.lower)) - float32(y0)
+
+	g00 := board.calculateGradient(x0, y0)
+	n00x, n00y := toXY(g00, sx, sy)
+	n00 := n00x + n00y
+
+	g10 := board.calculateGradient(x1, y0)
+	n10x, n10y := toXY(g10, sx-1, sy)
+	n10 := n10x + n10y
+
+	g01 := board.calculateGradient(x0, y1)
+	n01x, n01y := toXY(g01, sx, sy-1)
+	n01 := n01x + n01y
+
+	g11 := board.calculateGradient(x1, y1)
+	n11x, n11y := toXY(g11, sx-1, sy-1)
+	n11 := n11x + n11y
+
+	fu := fade(sx)
+
+	nx0 := (n00 * (1 - fu)) + (n10 * fu)
+	nx1 := (n01 * (1 - fu)) + (n11 * fu)
+
+	fv := fade(sy)
+
+	nxy := (nx0 * (1 - fv)) + (nx1 * fv)
+
+	// Each dot product changes with x and y by the components of its gradient
+	g00x, g00y := toXY(g00, 1, 1)
+	g10x, g10y := toXY(g10, 1, 1)
+	g01x, g01y := toXY(g01, 1, 1)
+	g11x, g11y := toXY(g11, 1, 1)
+
+	dfu := fadeDerivative(sx)
+	dfv := fadeDerivative(sy)
+
+	dnx0dx := (g00x * (1 - fu)) + (g10x * fu) + (n10-n00)*dfu
+	dnx1dx := (g01x * (1 - fu)) + (g11x * fu) + (n11-n01)*dfu
+	dnx0dy := (g00y * (1 - fu)) + (g10y * fu)
+	dnx1dy := (g01y * (1 - fu)) + (g11y * fu)
+
+	dnxydx := (dnx0dx * (1 - fv)) + (dnx1dx * fv)
+	dnxydy := (dnx0dy * (1 - fv)) + (dnx1dy * fv) + (nx1-nx0)*dfv
+	return nxy, dnxydx, dnxydy
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////////
+//=============================================Terrains==============================================//
+///////////////////////////////////////////////////////////////////////////////////////////////////////
+// Interface for terrains that can be progressivley generated
+type Terrain interface {
+	// Samples the height of every vertex of the terrain's grid into its height field
+	Generate()
+	// The grid of vertices the terrain is sampled on
+	Grid() SamplingGrid
+	// The heights of the terrain at every vertex of its grid
+	HeightField() *HeightField
+	// The height of the terrain at a column and row of its grid, including ones outside of it
+	HeightAt(col, row int) float32
+	MoveUp(int)
+	MoveDown(int)
+	MoveLeft(int)
+	MoveRight(int)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////
+//===========================================terrainBase===========================================//
+/////////////////////////////////////////////////////////////////////////////////////////////////////
+// The height field and displacement every terrain keeps. Terrains embed it for the methods of Terrain that only depend on
+// the heights of their vertices, and set it up with the HeightAt the terrain samples its heights with.
+type terrainBase struct {
+	// The heights of this terrain at every vertex of its grid
+	field *HeightField
+	// The grid the vertices of the terrain are placed on
+	grid SamplingGrid
+	// The current displacement from x=0 and y=0 of the sampled terrain, in grid cells
+	xDisp int
+	yDisp int
+	// The height of the terrain at a column and row of its grid, the HeightAt of the terrain that embeds it
+	heightAt func(col, row int) float32
+}
+
+/*
+ * Sets up the height field of a terrain on its grid and generates its heights
+ * @param grid The grid the vertices of the terrain are placed on
+ * @param terrainWidth The number of vertices to be sampled in the x direction of the terrain
+ * @param terrainHeight The number of vertices to be sampled in the y direction of the terrain
+ * @param heightAt The HeightAt of the terrain
+ */
+func (base *terrainBase) init(grid SamplingGrid, terrainWidth, terrainHeight uint32, heightAt func(col, row int) float32) {
+	base.grid = grid
+	base.heightAt = heightAt
+	base.field = NewHeightField(terrainWidth, terrainHeight, grid.extent(0, 0))
+	base.Generate()
+}
+
+// Samples the height of every vertex of the terrain's grid
+func (base *terrainBase) Generate() {
+	base.field.fill(base.heightAt)
+}
+
+// The grid the vertices of the terrain are placed on
+func (base *terrainBase) Grid() SamplingGrid {
+	return base.grid
+}
+
+// The heights of the terrain at every vertex of its grid
+func (base *terrainBase) HeightField() *HeightField {
+	return base.field
+}
+
+/*
+ * Determines new terrain surface heights when displaced from the current configuration by the amount parameter in the -x direction
+ */
+func (base *terrainBase) MoveLeft(amount int) {
+	base.move(amount, 0)
+}
+
+/*
+ * Determines new terrain surface heights when displaced from the current configuration by the amount parameter in the +x direction
+ */
+func (base *terrainBase) MoveRight(amount int) {
+	base.move(amount, 0)
+}
+
+/*
+ * Determines new terrain surface heights when displaced from the current configuration by the amount parameter in the -y direction
+ */
+func (base *terrainBase) MoveDown(amount int) {
+	base.move(0, amount)
+}
+
+/*
+ * Determines new terrain surface heights when displaced from the current configuration by the amount parameter in the +y direction
+ */
+func (base *terrainBase) MoveUp(amount int) {
+	base.move(0, amount)
+}
+
+/*
+ * Displaces the terrain by a number of grid cells, sampling only the vertices that move onto the grid
+ * @param dx The displacement in the x direction
+ * @param dy The displacement in the y direction
+ */
+func (base *terrainBase) move(dx, dy int) {
+	base.xDisp += dx
+	base.yDisp += dy
+	base.field.shift(dx, dy, base.grid.extent(base.xDisp, base.yDisp), base.heightAt)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+//==========================================SimpleTerrain===========================================//
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+type SimpleTerrain struct {
+	// The height field, grid and displacement of this terrain, the grid spans the gradient board's bounds
+	terrainBase
+	// The gradient board of this terrain used by the perlin noise generation
+	board GradientBoard
+	// The distortion applied to the coordinates the board is sampled at
+	warp DomainWarp
+	// The magnitude of this terrain
+	m float32
+}
+
+/*
+ * Creates a simple terrain and generates its heights
+ * @param board The gradient board used to generate the perlin noise textures
+ * @param warp The distortion applied to the coordinates the board is sampled at
+ * @param terrainWidth The number of vertices to be sampled in the x direction of the terrain
+ * @param terrainHeight The number of vertices to be sampled in the y direction of the terrain
+ * @param m The magnitude of the terrain
+ */
+func NewSimpleTerrain(board GradientBoard, warp DomainWarp, terrainWidth, terrainHeight uint32, m float32) *SimpleTerrain {
+	terrain := &SimpleTerrain{
+		board: board,
+		warp:  warp,
+		m:     m,
+	}
+	terrain.init(NewSamplingGrid(board.xBounds, board.yBounds, terrainWidth, terrainHeight), terrainWidth, terrainHeight, terrain.HeightAt)
+	return terrain
+}
+
+/*
+ * Calculates the height of the terrain at a column and row of its grid, taking the current displacement into account
+ * @param col The column of the vertex in the grid
+ * @param row The row of the vertex in the grid
+ */
+func (terrain *SimpleTerrain) HeightAt(col, row int) float32 {
+	x, y := terrain.warp.Apply(terrain.grid.X(col+terrain.xDisp), terrain.grid.Y(row+terrain.yDisp))
+	return terrain.board.Noise(x, y) * terrain.m
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//======================================BipartiteTerrain======================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+type BipartiteTerrain struct {
+	// The height field, grid and displacement of this terrain, the grid is sampled from the macro board
+	terrainBase
+	// The macro textures gradient board of this terrain used by the perlin noise generation
+	macro GradientBoard
+	// The micro textures gradient board of this terrain used by the perlin noise generation
+	micro GradientBoard
+	// The distortion applied to the coordinates of the macro board, the micro board is moved by the same number of vertices
+	warp DomainWarp
+	// The grid of vertices sampled from the micro board. It shares the terrain's width and height with the grid of the
+	// macro board so that every vertex samples both boards at the same relative position.
+	microGrid SamplingGrid
+	// The magnitude of this terrain
+	m float32
+	// The effect of the macro texture generation on the surface geometry opposed to the effect of the micro texture generation
+	prop float32
+}
+
+/*
+ * Creates a bipartite terrain and generates its heights
+ * @param macro The gradient board used to generate the macro perlin noise textures
+ * @param micro The gradient board used to generate the micro perlin noise textures
+ * @param warp The distortion applied to the coordinates of the macro board, the micro board is moved by the same number of vertices
+ * @param terrainWidth The number of vertices to be sampled in the x direction of the terrain
+ * @param terrainHeight The number of vertices to be sampled in the y direction of the terrain
+ * @param m The magnitude of the terrain
+ * @param prop The effect of the macro texture generation on the surface geometry opposed to the effect of the micro texture generation
+ */
+func NewBipartiteTerrain(macro, micro GradientBoard, warp DomainWarp, terrainWidth, terrainHeight uint32, m, prop float32) *BipartiteTerrain {
+	terrain := &BipartiteTerrain{
+		macro:     macro,
+		micro:     micro,
+		warp:      warp,
+		microGrid: NewSamplingGrid(micro.xBounds, micro.yBounds, terrainWidth, terrainHeight),
+		m:         m,
+		prop:      prop,
+	}
+	terrain.init(NewSamplingGrid(macro.xBounds, macro.yBounds, terrainWidth, terrainHeight), terrainWidth, terrainHeight, terrain.HeightAt)
+	return terrain
+}
+
+/*
+ * Calculates the height of the terrain at a column and row of its grid, taking the current displacement into account
+ * @param col The column of the vertex in the grid
+ * @param row The row of the vertex in the grid
+ */
+func (terrain *BipartiteTerrain) HeightAt(col, row int) float32 {
+	col, row = col+terrain.xDisp, row+terrain.yDisp
+	x1, y1 := terrain.grid.X(col), terrain.grid.Y(row)
+	x2, y2 := terrain.microGrid.X(col), terrain.microGrid.Y(row)
+	wx1, wy1 := terrain.warp.Apply(x1, y1)
+	dx2, dy2 := terrain.grid.scaleTo(terrain.microGrid, wx1-x1, wy1-y1)
+	height1 := terrain.macro.Noise(wx1, wy1) * terrain.prop
+	height2 := terrain.micro.Noise(x2+dx2, y2+dy2) * (1 - terrain.prop)
+	return (height1 + height2) * terrain.m
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//============================================Math============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+
+/*
+ * Takes in an angle and determines the unit circle x and y displacements given sx and sy
+ *
+ */
+func toXY(gradient uint16, sx, sy float32) (float32, float32) {
+	return float32(math.Cos(float64(gradient)*(math.Pi/180))) * sx, float32(math.Sin(float64(gradient)*(math.Pi/180))) * sy
+}
+
+/*
+ * The quintic fade curve 6t^5 - 15t^4 + 10t^3 used to interpolate between the gradients of the perlin noise algorithm
+ * @param t The distance from the lower gradient, in [0, 1]
+ */
+func fade(t float32) float32 {
+	return float32((6.0 * math.Pow(float64(t), 5.0)) - (15.0 * math.Pow(float64(t), 4.0)) + (10.0 * math.Pow(float64(t), 3.0)))
+}
+
+/*
+ * The derivative 30t^4 - 60t^3 + 30t^2 of the quintic fade curve
+ * @param t The distance from the lower gradient, in [0, 1]
+ */
+func fadeDerivative(t float32) float32 {
+	return 30 * t * t * (t*(t-2) + 1)
+}
+
+// The absolute value of a float32
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// The sign (-1, 0 or 1) of a float32
+func sign(v float32) float32 {
+	if v < 0 {
+		return -1
+	} else if v > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package terrain
+
+import (
+	"testing"
+)
+
+// Every terrain keeps its height field equal to its heights at its displacement as it is moved, and Generate samples the
+// same heights again
+func TestTerrainMoves(t *testing.T) {
+	terrains := map[string]func() Terrain{
+		"simple": func() Terrain { return NewSimpleTerrain(NewGradientBoard(5, 5, 43), DomainWarp{}, 17, 13, 1) },
+		"bipartite": func() Terrain {
+			return NewBipartiteTerrain(NewGradientBoard(5, 5, 43), NewGradientBoard(27, 27, 97), DomainWarp{}, 17, 13, 1, 0.9)
+		},
+		"fractal": func() Terrain { return NewFractalTerrain(NewFractalBoard(5, 5, 43, 3, 2, 0.5), DomainWarp{}, 17, 13, 1) },
+		"layered": func() Terrain {
+			layers := []TerrainLayer{
+				NewTerrainLayer(NewFractalBoard(5, 5, 43, 1, 2, 0.5), 1, 0, BlendAdd, 0),
+				NewTerrainLayer(NewFractalBoard(9, 9, 97, 2, 2, 0.5), 0.3, 0, BlendAdd, 0),
+			}
+			return NewLayeredTerrain(layers, DomainWarp{}, 17, 13, 1)
+		},
+	}
+	for name, newTerrain := range terrains {
+		reference := newTerrain()
+		terrain := newTerrain()
+		terrain.MoveRight(3)
+		terrain.MoveUp(-2)
+		terrain.MoveLeft(-1)
+		terrain.MoveDown(5)
+		field := terrain.HeightField()
+		for row := 0; row < int(field.Height()); row++ {
+			for col := 0; col < int(field.Width()); col++ {
+				if h, want := field.At(col, row), reference.HeightAt(col+2, row+3); h != want {
+					t.Fatalf("%s: the height at (%d, %d) after moving is %v, expected %v", name, col, row, h, want)
+				}
+			}
+		}
+		if extent, want := field.Extent(), reference.Grid().extent(2, 3); extent != want {
+			t.Errorf("%s: the extent after moving is %+v, expected %+v", name, extent, want)
+		}
+
+		moved := append([]float32(nil), field.Heights()...)
+		terrain.Generate()
+		for i, h := range terrain.HeightField().Heights() {
+			if h != moved[i] {
+				t.Fatalf("%s: height %d is %v after Generate, %v before", name, i, h, moved[i])
+			}
+		}
+	}
+}
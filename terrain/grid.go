@@ -0,0 +1,102 @@
+package terrain
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//========================================SamplingGrid========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// A sampling grid maps integer (column, row) vertex indices onto the coordinates of a gradient board.
+// Every coordinate is derived from its index rather than accumulated, so any width and height produce
+// an exact width x height grid of vertices whose last row and column land on the board's upper bounds.
+type SamplingGrid struct {
+	xBounds Bounds
+	yBounds Bounds
+	// The number of vertices sampled in the x direction
+	width uint32
+	// The number of vertices sampled in the y direction
+	height uint32
+}
+
+/*
+ * Creates a sampling grid of width x height vertices spanning a pair of bounds
+ * @param xBounds The board coordinates spanned by the grid in the x direction
+ * @param yBounds The board coordinates spanned by the grid in the y direction
+ * @param width The number of vertices sampled in the x direction
+ * @param height The number of vertices sampled in the y direction
+ */
+func NewSamplingGrid(xBounds, yBounds Bounds, width, height uint32) SamplingGrid {
+	return SamplingGrid{xBounds: xBounds, yBounds: yBounds, width: width, height: height}
+}
+
+// The number of vertices sampled in the x direction
+func (grid SamplingGrid) Width() uint32 {
+	return grid.width
+}
+
+// The number of vertices sampled in the y direction
+func (grid SamplingGrid) Height() uint32 {
+	return grid.height
+}
+
+// The board coordinates spanned by the grid in the x and y directions
+func (grid SamplingGrid) Bounds() (Bounds, Bounds) {
+	return grid.xBounds, grid.yBounds
+}
+
+/*
+ * Returns the board x coordinate of a column. Columns outside [0, width) continue the grid at the same spacing.
+ * @param col The column index of the vertex
+ */
+func (grid SamplingGrid) X(col int) float32 {
+	return float32(gridCoordinate(grid.xBounds, grid.width, col))
+}
+
+/*
+ * Returns the board y coordinate of a row. Rows outside [0, height) continue the grid at the same spacing.
+ * @param row The row index of the vertex
+ */
+func (grid SamplingGrid) Y(row int) float32 {
+	return float32(gridCoordinate(grid.yBounds, grid.height, row))
+}
+
+/*
+ * Returns the board coordinates spanned by the vertices of the grid when it is displaced by a number of columns and rows
+ * @param cols The number of columns the grid is displaced by
+ * @param rows The number of rows the grid is displaced by
+ */
+func (grid SamplingGrid) extent(cols, rows int) Extent {
+	return Extent{
+		MinX: gridCoordinate(grid.xBounds, grid.width, cols),
+		MinY: gridCoordinate(grid.yBounds, grid.height, rows),
+		MaxX: gridCoordinate(grid.xBounds, grid.width, cols+int(grid.width)-1),
+		MaxY: gridCoordinate(grid.yBounds, grid.height, rows+int(grid.height)-1),
+	}
+}
+
+/*
+ * Scales a distance in the board coordinates of this grid to the same distance in columns and rows in the board coordinates
+ * of another grid with the same number of vertices
+ * @param other The grid the distance is scaled to
+ * @param dx The distance in the x direction, in the board coordinates of this grid
+ * @param dy The distance in the y direction, in the board coordinates of this grid
+ */
+func (grid SamplingGrid) scaleTo(other SamplingGrid, dx, dy float32) (float32, float32) {
+	if grid.xBounds.Size() != 0 {
+		dx *= float32(other.xBounds.Size()) / float32(grid.xBounds.Size())
+	}
+	if grid.yBounds.Size() != 0 {
+		dy *= float32(other.yBounds.Size()) / float32(grid.yBounds.Size())
+	}
+	return dx, dy
+}
+
+/*
+ * Spreads count vertices evenly across bounds, placing the first on the lower bound and the last on the upper bound
+ * @param bounds The coordinates spanned by the vertices
+ * @param count The number of vertices spread across the bounds
+ * @param i The index of the vertex
+ */
+func gridCoordinate(bounds Bounds, count uint32, i int) float64 {
+	if count < 2 {
+		return float64(bounds.lower)
+	}
+	return float64(bounds.lower) + float64(i)*float64(bounds.upper-bounds.lower)/float64(count-1)
+}
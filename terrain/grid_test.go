@@ -0,0 +1,56 @@
+package terrain
+
+import "testing"
+
+// The vertex counts and gradient counts the grid and mesh tests are run for, odd and even, small and large
+var (
+	testSizes     = []uint32{2, 3, 7, 10, 33, 124, 125}
+	testGradients = []uint32{3, 5, 27}
+)
+
+// The first and last column and row of a grid land exactly on the bounds of its board
+func TestSamplingGridSpansBounds(t *testing.T) {
+	for _, gradients := range testGradients {
+		board := NewGradientBoard(gradients, gradients, 43)
+		for _, width := range testSizes {
+			for _, height := range testSizes {
+				grid := NewSamplingGrid(board.xBounds, board.yBounds, width, height)
+				if grid.Width() != width || grid.Height() != height {
+					t.Fatalf("%d gradients, %dx%d: grid is %dx%d", gradients, width, height, grid.Width(), grid.Height())
+				}
+				if x := grid.X(0); x != float32(board.xBounds.lower) {
+					t.Errorf("%d gradients, %dx%d: first column at %v, expected %d", gradients, width, height, x, board.xBounds.lower)
+				}
+				if x := grid.X(int(width) - 1); x != float32(board.xBounds.upper) {
+					t.Errorf("%d gradients, %dx%d: last column at %v, expected %d", gradients, width, height, x, board.xBounds.upper)
+				}
+				if y := grid.Y(0); y != float32(board.yBounds.lower) {
+					t.Errorf("%d gradients, %dx%d: first row at %v, expected %d", gradients, width, height, y, board.yBounds.lower)
+				}
+				if y := grid.Y(int(height) - 1); y != float32(board.yBounds.upper) {
+					t.Errorf("%d gradients, %dx%d: last row at %v, expected %d", gradients, width, height, y, board.yBounds.upper)
+				}
+			}
+		}
+	}
+}
+
+// A terrain fills a height field of exactly the size it was created with, whose extent is the bounds of its board
+func TestTerrainFieldSize(t *testing.T) {
+	for _, gradients := range testGradients {
+		for _, width := range testSizes {
+			for _, height := range testSizes {
+				board := NewGradientBoard(gradients, gradients, 43)
+				field := NewSimpleTerrain(board, DomainWarp{}, width, height, 1).HeightField()
+				if field.Width() != width || field.Height() != height || len(field.Heights()) != int(width*height) {
+					t.Fatalf("%d gradients, %dx%d: field is %dx%d with %d heights", gradients, width, height, field.Width(), field.Height(), len(field.Heights()))
+				}
+				extent := field.Extent()
+				if extent.MinX != float64(board.xBounds.lower) || extent.MaxX != float64(board.xBounds.upper) ||
+					extent.MinY != float64(board.yBounds.lower) || extent.MaxY != float64(board.yBounds.upper) {
+					t.Errorf("%d gradients, %dx%d: extent %+v does not match the bounds of the board", gradients, width, height, extent)
+				}
+			}
+		}
+	}
+}
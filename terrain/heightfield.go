@@ -0,0 +1,326 @@
+package terrain
+
+import (
+	"fmt"
+	"math"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//===========================================Extent===========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The world coordinates covered by a height field. The first column and row of the field lie on MinX and MinY and
+// the last ones on MaxX and MaxY. Terrains use the coordinates of their gradient boards as world coordinates.
+type Extent struct {
+	MinX, MinY float64
+	MaxX, MaxY float64
+}
+
+// The distance covered by the extent in the x direction
+func (extent Extent) Width() float64 {
+	return extent.MaxX - extent.MinX
+}
+
+// The distance covered by the extent in the y direction
+func (extent Extent) Height() float64 {
+	return extent.MaxY - extent.MinY
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//========================================HeightField=========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// A height field holds the height of a terrain at every vertex of its sampling grid, in row major order from the
+// lower x and y corner of its extent. It is the output of every terrain and does not depend on how, or whether, the
+// terrain is rendered, so meshes, exporters and analysis tools all read their heights from it.
+type HeightField struct {
+	// The number of vertices in the x direction
+	width uint32
+	// The number of vertices in the y direction
+	height uint32
+	// The world coordinates covered by the vertices
+	extent Extent
+	// The height of every vertex, row by row from the lower y bound
+	heights []float32
+}
+
+/*
+ * Creates a height field of width x height vertices that are all at height 0
+ * @param width The number of vertices in the x direction
+ * @param height The number of vertices in the y direction
+ * @param extent The world coordinates covered by the vertices
+ */
+func NewHeightField(width, height uint32, extent Extent) *HeightField {
+	return &HeightField{width: width, height: height, extent: extent, heights: make([]float32, int(width)*int(height))}
+}
+
+/*
+ * Creates a height field from float64 heights, which are stored as float32. It returns an error if there are not exactly
+ * width x height heights.
+ * @param width The number of vertices in the x direction
+ * @param height The number of vertices in the y direction
+ * @param extent The world coordinates covered by the vertices
+ * @param heights The width x height heights of the vertices in row major order
+ */
+func NewHeightFieldFromFloat64(width, height uint32, extent Extent, heights []float64) (*HeightField, error) {
+	if len(heights) != int(width)*int(height) {
+		return nil, fmt.Errorf("a %dx%d height field needs %d heights, not %d", width, height, int(width)*int(height), len(heights))
+	}
+	field := NewHeightField(width, height, extent)
+	for i := range field.heights {
+		field.heights[i] = float32(heights[i])
+	}
+	return field, nil
+}
+
+// The number of vertices in the x direction
+func (field *HeightField) Width() uint32 {
+	return field.width
+}
+
+// The number of vertices in the y direction
+func (field *HeightField) Height() uint32 {
+	return field.height
+}
+
+// The world coordinates covered by the vertices of the field
+func (field *HeightField) Extent() Extent {
+	return field.extent
+}
+
+/*
+ * Changes the world coordinates covered by the vertices of the field without changing their heights
+ * @param extent The world coordinates covered by the vertices
+ */
+func (field *HeightField) SetExtent(extent Extent) {
+	field.extent = extent
+}
+
+// The world distance between neighbouring columns and between neighbouring rows of the field
+func (field *HeightField) Spacing() (float64, float64) {
+	dx, dy := float64(0), float64(0)
+	if field.width > 1 {
+		dx = field.extent.Width() / float64(field.width-1)
+	}
+	if field.height > 1 {
+		dy = field.extent.Height() / float64(field.height-1)
+	}
+	return dx, dy
+}
+
+/*
+ * Returns the world x coordinate of a column. Columns outside [0, width) continue the field at the same spacing.
+ * @param col The column index of the vertex
+ */
+func (field *HeightField) X(col int) float64 {
+	dx, _ := field.Spacing()
+	return field.extent.MinX + float64(col)*dx
+}
+
+/*
+ * Returns the world y coordinate of a row. Rows outside [0, height) continue the field at the same spacing.
+ * @param row The row index of the vertex
+ */
+func (field *HeightField) Y(row int) float64 {
+	_, dy := field.Spacing()
+	return field.extent.MinY + float64(row)*dy
+}
+
+// The heights of every vertex in row major order. The slice is shared with the field, so it changes when the terrain moves.
+func (field *HeightField) Heights() []float32 {
+	return field.heights
+}
+
+// A float64 copy of the heights of every vertex in row major order
+func (field *HeightField) Float64s() []float64 {
+	heights := make([]float64, len(field.heights))
+	for i, h := range field.heights {
+		heights[i] = float64(h)
+	}
+	return heights
+}
+
+// The lowest and the highest height of the field, both 0 for a field without any vertices
+func (field *HeightField) Range() (float32, float32) {
+	if len(field.heights) == 0 {
+		return 0, 0
+	}
+	low, high := field.heights[0], field.heights[0]
+	for _, h := range field.heights[1:] {
+		if h < low {
+			low = h
+		} else if h > high {
+			high = h
+		}
+	}
+	return low, high
+}
+
+/*
+ * Returns the position of a vertex in the row major heights of the field
+ * @param col The column index of the vertex
+ * @param row The row index of the vertex
+ */
+func (field *HeightField) index(col, row int) int {
+	return row*int(field.width) + col
+}
+
+/*
+ * Returns whether a column and row are a vertex of the field
+ * @param col The column index of the vertex
+ * @param row The row index of the vertex
+ */
+func (field *HeightField) Contains(col, row int) bool {
+	return col >= 0 && col < int(field.width) && row >= 0 && row < int(field.height)
+}
+
+/*
+ * Returns the height of a vertex, the column and row must be inside the field
+ * @param col The column index of the vertex
+ * @param row The row index of the vertex
+ */
+func (field *HeightField) At(col, row int) float32 {
+	return field.heights[field.index(col, row)]
+}
+
+/*
+ * Returns the height of a vertex as a float64. Columns and rows outside the field take the height of the closest edge vertex.
+ * @param col The column index of the vertex
+ * @param row The row index of the vertex
+ */
+func (field *HeightField) Float64At(col, row int) float64 {
+	col = clampIndex(col, int(field.width))
+	row = clampIndex(row, int(field.height))
+	return float64(field.heights[field.index(col, row)])
+}
+
+/*
+ * Changes the height of a vertex, the column and row must be inside the field
+ * @param col The column index of the vertex
+ * @param row The row index of the vertex
+ * @param h The new height of the vertex
+ */
+func (field *HeightField) Set(col, row int, h float32) {
+	field.heights[field.index(col, row)] = h
+}
+
+/*
+ * Converts a world position into a fractional column and row of the field
+ * @param x The world x coordinate
+ * @param y The world y coordinate
+ */
+func (field *HeightField) cell(x, y float64) (float64, float64) {
+	dx, dy := field.Spacing()
+	col, row := float64(0), float64(0)
+	if dx != 0 {
+		col = (x - field.extent.MinX) / dx
+	}
+	if dy != 0 {
+		row = (y - field.extent.MinY) / dy
+	}
+	return col, row
+}
+
+/*
+ * Looks up the height of the vertex closest to a world position, positions outside the extent take the height of the closest edge
+ * @param x The world x coordinate
+ * @param y The world y coordinate
+ */
+func (field *HeightField) Sample(x, y float64) float64 {
+	col, row := field.cell(x, y)
+	return field.Float64At(int(math.Round(col)), int(math.Round(row)))
+}
+
+/*
+ * Interpolates the height at a world position linearly between the four vertices around it, positions outside the extent
+ * take the height of the closest edge
+ * @param x The world x coordinate
+ * @param y The world y coordinate
+ */
+func (field *HeightField) Bilinear(x, y float64) float64 {
+	col, row := field.cell(x, y)
+	col0, row0 := math.Floor(col), math.Floor(row)
+	tx, ty := col-col0, row-row0
+	c, r := int(col0), int(row0)
+	h0 := field.Float64At(c, r)*(1-tx) + field.Float64At(c+1, r)*tx
+	h1 := field.Float64At(c, r+1)*(1-tx) + field.Float64At(c+1, r+1)*tx
+	return h0*(1-ty) + h1*ty
+}
+
+/*
+ * Interpolates the height at a world position with a Catmull-Rom spline through the sixteen vertices around it, which is
+ * smoother than Bilinear when a field is resampled at a higher resolution. Positions outside the extent take the height
+ * of the closest edge.
+ * @param x The world x coordinate
+ * @param y The world y coordinate
+ */
+func (field *HeightField) Bicubic(x, y float64) float64 {
+	col, row := field.cell(x, y)
+	col0, row0 := math.Floor(col), math.Floor(row)
+	tx, ty := col-col0, row-row0
+	c, r := int(col0), int(row0)
+	var rows [4]float64
+	for j := 0; j < 4; j++ {
+		rows[j] = catmullRom(field.Float64At(c-1, r+j-1), field.Float64At(c, r+j-1), field.Float64At(c+1, r+j-1), field.Float64At(c+2, r+j-1), tx)
+	}
+	return catmullRom(rows[0], rows[1], rows[2], rows[3], ty)
+}
+
+/*
+ * Samples the height of every vertex of the field
+ * @param heightAt Produces the terrain height of the vertex at a column and row
+ */
+func (field *HeightField) fill(heightAt func(col, row int) float32) {
+	for row := 0; row < int(field.height); row++ {
+		for col := 0; col < int(field.width); col++ {
+			field.Set(col, row, heightAt(col, row))
+		}
+	}
+}
+
+/*
+ * Moves the heights of the field by a whole number of columns and rows. Heights that are still inside the field are
+ * reused and only the vertices that are uncovered are sampled again.
+ * @param cols The number of columns to move the heights by, a vertex takes the height of the vertex cols to its right
+ * @param rows The number of rows to move the heights by, a vertex takes the height of the vertex rows above it
+ * @param extent The world coordinates covered by the vertices after moving
+ * @param heightAt Produces the terrain height of the vertex at a column and row after moving
+ */
+func (field *HeightField) shift(cols, rows int, extent Extent, heightAt func(col, row int) float32) {
+	field.extent = extent
+	previous := make([]float32, len(field.heights))
+	copy(previous, field.heights)
+	for row := 0; row < int(field.height); row++ {
+		for col := 0; col < int(field.width); col++ {
+			if field.Contains(col+cols, row+rows) {
+				field.Set(col, row, previous[field.index(col+cols, row+rows)])
+			} else {
+				field.Set(col, row, heightAt(col, row))
+			}
+		}
+	}
+}
+
+/*
+ * Clamps an index into [0, count)
+ * @param i The index to clamp
+ * @param count The number of valid indices
+ */
+func clampIndex(i, count int) int {
+	if i < 0 {
+		return 0
+	} else if i >= count {
+		return count - 1
+	}
+	return i
+}
+
+/*
+ * The Catmull-Rom spline through four evenly spaced values, evaluated between the middle two
+ * @param p0 The value before the interval
+ * @param p1 The value at the start of the interval
+ * @param p2 The value at the end of the interval
+ * @param p3 The value after the interval
+ * @param t The position in the interval, in [0, 1]
+ */
+func catmullRom(p0, p1, p2, p3, t float64) float64 {
+	return p1 + 0.5*t*(p2-p0+t*(2*p0-5*p1+4*p2-p3+t*(3*(p1-p2)+p3-p0)))
+}
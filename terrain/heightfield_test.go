@@ -0,0 +1,94 @@
+package terrain
+
+import (
+	"math"
+	"testing"
+)
+
+// A height field is only created from exactly width x height heights
+func TestNewHeightFieldFromFloat64(t *testing.T) {
+	extent := Extent{MaxX: 2, MaxY: 1}
+	for _, n := range []int{0, 5, 7} {
+		if _, err := NewHeightFieldFromFloat64(3, 2, extent, make([]float64, n)); err == nil {
+			t.Errorf("%d heights made a 3x2 height field", n)
+		}
+	}
+	field, err := NewHeightFieldFromFloat64(3, 2, extent, []float64{0, 1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.At(2, 1) != 5 || field.At(0, 1) != 3 {
+		t.Errorf("heights are not row major, (2, 1) is %v and (0, 1) is %v", field.At(2, 1), field.At(0, 1))
+	}
+}
+
+/*
+ * Creates a 6x5 height field of the plane 2x + 3y + 1 over the extent from (10, 20) to (15, 24), one unit between vertices
+ * @param t The test
+ */
+func rampField(t *testing.T) *HeightField {
+	heights := make([]float64, 0, 6*5)
+	for row := 0; row < 5; row++ {
+		for col := 0; col < 6; col++ {
+			heights = append(heights, 2*float64(col)+3*float64(row)+1)
+		}
+	}
+	field, err := NewHeightFieldFromFloat64(6, 5, Extent{MinX: 10, MinY: 20, MaxX: 15, MaxY: 24}, heights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return field
+}
+
+// Every lookup returns the height of a vertex at its position, and the height of the closest edge outside of the extent
+func TestHeightFieldLookupsOnVertices(t *testing.T) {
+	field := rampField(t)
+	lookups := map[string]func(x, y float64) float64{"Sample": field.Sample, "Bilinear": field.Bilinear, "Bicubic": field.Bicubic}
+	for name, lookup := range lookups {
+		for row := 0; row < 5; row++ {
+			for col := 0; col < 6; col++ {
+				if h := lookup(field.X(col), field.Y(row)); h != field.Float64At(col, row) {
+					t.Errorf("%s: the vertex (%d, %d) is %v, expected %v", name, col, row, h, field.Float64At(col, row))
+				}
+			}
+		}
+		clamped := []struct{ x, y, h float64 }{
+			{0, 0, field.Float64At(0, 0)},
+			{100, 100, field.Float64At(5, 4)},
+			{12, -50, field.Float64At(2, 0)},
+			{-50, 23, field.Float64At(0, 3)},
+		}
+		for _, c := range clamped {
+			if h := lookup(c.x, c.y); h != c.h {
+				t.Errorf("%s: (%v, %v) outside of the extent is %v, expected the edge height %v", name, c.x, c.y, h, c.h)
+			}
+		}
+	}
+}
+
+// Bilinear interpolates linearly between vertices, and Bicubic reproduces a plane exactly away from the edges
+func TestHeightFieldInterpolation(t *testing.T) {
+	field := rampField(t)
+	plane := func(x, y float64) float64 {
+		return 2*(x-10) + 3*(y-20) + 1
+	}
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 5; col++ {
+			x, y := field.X(col)+0.5, field.Y(row)+0.5
+			if h := field.Bilinear(x, y); math.Abs(h-plane(x, y)) > 1e-9 {
+				t.Errorf("Bilinear: the middle of the cell (%d, %d) is %v, expected %v", col, row, h, plane(x, y))
+			}
+			if h, want := field.Bilinear(x, field.Y(row)), (field.Float64At(col, row)+field.Float64At(col+1, row))/2; math.Abs(h-want) > 1e-9 {
+				t.Errorf("Bilinear: the middle of the edge from (%d, %d) is %v, expected %v", col, row, h, want)
+			}
+		}
+	}
+	// The spline of a cell reads one vertex on either side of it, so only the cells inside the first and last ones are exact
+	for y := 21.0; y <= 23; y += 0.25 {
+		for x := 11.0; x <= 14; x += 0.25 {
+			if h := field.Bicubic(x, y); math.Abs(h-plane(x, y)) > 1e-9 {
+				t.Errorf("Bicubic: (%v, %v) is %v, expected %v", x, y, h, plane(x, y))
+			}
+		}
+	}
+}
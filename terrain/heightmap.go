@@ -0,0 +1,121 @@
+package terrain
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=========================================Heightmap==========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// A heightmap is a height field that has been drawn or generated elsewhere, stretched over the bounds of a board so it
+// can be used as a layer of a LayeredTerrain in place of noise
+type Heightmap struct {
+	field   *HeightField
+	xBounds Bounds
+	yBounds Bounds
+}
+
+/*
+ * Creates a heightmap spanning the bounds a gradient board of the same size would have, so it lines up with the noise
+ * layers of that size. Positions outside of its bounds take the height of the closest edge.
+ * @param field The heights of the heightmap, its first row is the lowest y of the bounds
+ * @param gradientWidth The number of gradient cells the heightmap spans in the x direction, centered about the origin
+ * @param gradientHeight The number of gradient cells the heightmap spans in the y direction, centered about the origin
+ */
+func NewHeightmap(field *HeightField, gradientWidth, gradientHeight uint32) Heightmap {
+	board := NewGradientBoard(gradientWidth, gradientHeight, 0)
+	return Heightmap{field: field, xBounds: board.xBounds, yBounds: board.yBounds}
+}
+
+// The bounds the heightmap is stretched over in the x and y directions, the coordinates Noise is sampled in
+func (heightmap Heightmap) Bounds() (Bounds, Bounds) {
+	return heightmap.xBounds, heightmap.yBounds
+}
+
+/*
+ * Interpolates the height of the heightmap at a position between its vertices
+ * @param x The x position in the coordinates of the heightmap's bounds
+ * @param y The y position in the coordinates of the heightmap's bounds
+ */
+func (heightmap Heightmap) Noise(x, y float32) float32 {
+	extent := heightmap.field.Extent()
+	return float32(heightmap.field.Bilinear(
+		extent.MinX+boundsFraction(heightmap.xBounds, x)*extent.Width(),
+		extent.MinY+boundsFraction(heightmap.yBounds, y)*extent.Height(),
+	))
+}
+
+/*
+ * The fraction of the way from the lower to the upper bound of a coordinate
+ * @param bounds The bounds of the coordinate
+ * @param v The coordinate
+ */
+func boundsFraction(bounds Bounds, v float32) float64 {
+	if bounds.upper == bounds.lower {
+		return 0
+	}
+	return (float64(v) - float64(bounds.lower)) / (float64(bounds.upper) - float64(bounds.lower))
+}
+
+/*
+ * Reads an 8 or 16-bit png as a height field, black is 0 and white is 1. The colors of other pngs are converted to gray.
+ * The top row of the image is the highest row of the field, as it is in the heightmaps written by the export package.
+ * @param r The reader of the png
+ */
+func ReadHeightmapPNG(r io.Reader) (*HeightField, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	field := NewHeightField(uint32(bounds.Dx()), uint32(bounds.Dy()), Extent{MaxX: float64(bounds.Dx() - 1), MaxY: float64(bounds.Dy() - 1)})
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray := color.Gray16Model.Convert(img.At(x, y)).(color.Gray16)
+			field.Set(x-bounds.Min.X, bounds.Max.Y-1-y, float32(gray.Y)/math.MaxUint16)
+		}
+	}
+	return field, nil
+}
+
+/*
+ * Reads raw little-endian heights as a height field, row by row from the highest row to the lowest. 16-bit heights are
+ * unsigned and read from 0 to 1, like the .r16 heightmaps of game engines, and 32-bit heights are float32 read as they
+ * are, like the heightmaps written by export.WriteR32.
+ * @param r The reader of the heights
+ * @param bits The size of a height, 16 or 32
+ * @param width The number of heights in a row
+ * @param height The number of rows
+ */
+func ReadHeightmapRaw(r io.Reader, bits int, width, height uint32) (*HeightField, error) {
+	if bits != 16 && bits != 32 {
+		return nil, fmt.Errorf("raw heightmaps have 16 or 32-bit heights, not %d-bit", bits)
+	}
+	field := NewHeightField(width, height, Extent{MaxX: float64(width) - 1, MaxY: float64(height) - 1})
+	row := make([]float32, width)
+	raw := make([]uint16, width)
+	for j := int(height) - 1; j >= 0; j-- {
+		switch bits {
+		case 16:
+			if err := binary.Read(r, binary.LittleEndian, raw); err != nil {
+				return nil, err
+			}
+			for i, h := range raw {
+				row[i] = float32(h) / math.MaxUint16
+			}
+		case 32:
+			if err := binary.Read(r, binary.LittleEndian, row); err != nil {
+				return nil, err
+			}
+		}
+		for i, h := range row {
+			field.Set(i, j, h)
+		}
+	}
+	return field, nil
+}
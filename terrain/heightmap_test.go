@@ -0,0 +1,80 @@
+package terrain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+)
+
+// 8 and 16-bit pngs are read from black at 0 to white at 1, with the top row of the image as the highest row of the field
+func TestReadHeightmapPNG(t *testing.T) {
+	gray8 := image.NewGray(image.Rect(0, 0, 3, 2))
+	gray16 := image.NewGray16(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			gray8.SetGray(x, y, color.Gray{Y: uint8(51 * (x + 3*y))})
+			gray16.SetGray16(x, y, color.Gray16{Y: uint16(13107 * (x + 3*y))})
+		}
+	}
+	for name, img := range map[string]image.Image{"8-bit": gray8, "16-bit": gray16} {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatal(err)
+		}
+		field, err := ReadHeightmapPNG(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if field.Width() != 3 || field.Height() != 2 {
+			t.Fatalf("%s: the field is %dx%d, expected 3x2", name, field.Width(), field.Height())
+		}
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 3; x++ {
+				// The image rows are counted down from the top, the field rows up from the bottom
+				want := float32(x+3*y) / 5
+				if got := field.At(x, 1-y); math.Abs(float64(got-want)) > 1e-6 {
+					t.Errorf("%s: the pixel (%d, %d) is read as %v at row %d, expected %v", name, x, y, got, 1-y, want)
+				}
+			}
+		}
+	}
+}
+
+// Raw heights are read row by row from the highest row, 16-bit heights from 0 to 1 and 32-bit heights as they are
+func TestReadHeightmapRaw(t *testing.T) {
+	var r16, r32 bytes.Buffer
+	binary.Write(&r16, binary.LittleEndian, []uint16{math.MaxUint16, 0, 0, 0, math.MaxUint16 / 5, 0})
+	binary.Write(&r32, binary.LittleEndian, []float32{3, 4, 5, 0, 1.5, -2})
+	tests := []struct {
+		name    string
+		data    *bytes.Buffer
+		bits    int
+		heights []float32
+	}{
+		{"r16", &r16, 16, []float32{0, 0.2, 0, 1, 0, 0}},
+		{"r32", &r32, 32, []float32{0, 1.5, -2, 3, 4, 5}},
+	}
+	for _, test := range tests {
+		field, err := ReadHeightmapRaw(test.data, test.bits, 3, 2)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		for i, h := range field.Heights() {
+			if math.Abs(float64(h-test.heights[i])) > 1e-6 {
+				t.Errorf("%s: the heights are %v, expected %v", test.name, field.Heights(), test.heights)
+				break
+			}
+		}
+	}
+
+	if _, err := ReadHeightmapRaw(bytes.NewReader(make([]byte, 6)), 8, 3, 2); err == nil {
+		t.Error("8-bit raw heights were read")
+	}
+	if _, err := ReadHeightmapRaw(bytes.NewReader(make([]byte, 10)), 16, 3, 2); err == nil {
+		t.Error("5 16-bit heights were read as a 3x2 heightmap")
+	}
+}
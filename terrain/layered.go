@@ -0,0 +1,211 @@
+package terrain
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//===========================================Blend============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The operator used to combine a layer of a LayeredTerrain with the layers beneath it
+type Blend uint8
+
+const (
+	// Adds the layer to the layers beneath it
+	BlendAdd Blend = iota
+	// Multiplies the layers beneath by the layer
+	BlendMultiply
+	// Keeps the larger of the layer and the layers beneath it
+	BlendMax
+	// Keeps the smaller of the layer and the layers beneath it
+	BlendMin
+	// Interpolates from the layers beneath it to the layer by the value of the layer's mask, clamped to [0, 1]
+	BlendLerp
+	// Does not contribute to the terrain, the layer is only used as the mask of other layers
+	BlendNone
+)
+
+// The names of the blend operators as they are written in map files
+var blendNames = map[string]Blend{
+	"add":      BlendAdd,
+	"multiply": BlendMultiply,
+	"max":      BlendMax,
+	"min":      BlendMin,
+	"lerp":     BlendLerp,
+	"none":     BlendNone,
+}
+
+/*
+ * Finds the blend operator with a name from a map file
+ * @param name The name of the blend operator
+ */
+func ParseBlend(name string) (Blend, bool) {
+	blend, ok := blendNames[name]
+	return blend, ok
+}
+
+/*
+ * Combines the value of a layer with the value of the layers beneath it
+ * @param below The blended value of the layers beneath the layer
+ * @param value The value of the layer
+ * @param mask The value of the layer's mask, only used by BlendLerp
+ */
+func (blend Blend) Apply(below, value, mask float32) float32 {
+	switch blend {
+	case BlendAdd:
+		return below + value
+	case BlendMultiply:
+		return below * value
+	case BlendMax:
+		if value > below {
+			return value
+		}
+		return below
+	case BlendMin:
+		if value < below {
+			return value
+		}
+		return below
+	case BlendLerp:
+		t := mask
+		if t < 0 {
+			t = 0
+		} else if t > 1 {
+			t = 1
+		}
+		return below + (value-below)*t
+	}
+	return below
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//========================================TerrainLayer========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The source of the values of a terrain layer, a FractalBoard of noise or a Heightmap
+type LayerSource interface {
+	// The bounds of the source in the x and y directions, the coordinates Noise is sampled in
+	Bounds() (Bounds, Bounds)
+	// The value of the source at a position within its bounds
+	Noise(x, y float32) float32
+}
+
+type TerrainLayer struct {
+	// The source of the values of this layer, a single octave fbm board is plain noise of its gradient board
+	source LayerSource
+	// The grid of vertices sampled from the coarsest octave of the board, it is set up by the LayeredTerrain the layer belongs to
+	grid SamplingGrid
+	// The amplitude of the noise of this layer
+	weight float32
+	// The constant added to the noise of this layer after it is weighted
+	offset float32
+	// The operator combining this layer with the layers beneath it
+	blend Blend
+	// The index of the layer whose value is the interpolation factor of a BlendLerp layer
+	mask int
+}
+
+/*
+ * Creates a terrain layer, its grid is set up by the LayeredTerrain the layer is added to
+ * @param source The fractal board or heightmap the values of the layer are sampled from
+ * @param weight The amplitude of the noise of the layer
+ * @param offset The constant added to the noise of the layer after it is weighted
+ * @param blend The operator combining the layer with the layers beneath it
+ * @param mask The index of the layer whose value is the interpolation factor of a BlendLerp layer
+ */
+func NewTerrainLayer(source LayerSource, weight, offset float32, blend Blend, mask int) TerrainLayer {
+	return TerrainLayer{source: source, weight: weight, offset: offset, blend: blend, mask: mask}
+}
+
+// The fractal board or heightmap the values of the layer are sampled from
+func (layer TerrainLayer) Source() LayerSource {
+	return layer.source
+}
+
+/*
+ * Calculates the weighted and offset noise of the layer at a column and row of its grid
+ * @param col The column of the grid, including any displacement of the terrain
+ * @param row The row of the grid, including any displacement of the terrain
+ * @param dx The distance to move the sampled position in the x direction, in the board coordinates of the layer
+ * @param dy The distance to move the sampled position in the y direction, in the board coordinates of the layer
+ */
+func (layer TerrainLayer) value(col, row int, dx, dy float32) float32 {
+	return layer.source.Noise(layer.grid.X(col)+dx, layer.grid.Y(row)+dy)*layer.weight + layer.offset
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=======================================LayeredTerrain=======================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+type LayeredTerrain struct {
+	// The height field, grid and displacement of this terrain, the grid is the grid of the first layer
+	terrainBase
+	// The layers of this terrain, blended in order onto a height that starts at 0
+	layers []TerrainLayer
+	// The distortion applied to the coordinates of the first layer, the other layers are moved by the same number of vertices
+	warp DomainWarp
+	// The magnitude of this terrain
+	m float32
+}
+
+/*
+ * Creates a layered terrain and generates its heights. Every layer samples
+ * its own board with a grid of the terrain's width and height, and the vertices are placed on the grid of the first layer.
+ * @param layers The layers of the terrain. A BlendLerp layer whose mask is not the index of one of them interpolates by 0,
+ * so it leaves the layers beneath it as they are.
+ * @param warp The distortion applied to the coordinates of the first layer, the other layers are moved by the same number of vertices
+ * @param terrainWidth The number of vertices to be sampled in the x direction of the terrain
+ * @param terrainHeight The number of vertices to be sampled in the y direction of the terrain
+ * @param m The magnitude of the terrain
+ */
+func NewLayeredTerrain(layers []TerrainLayer, warp DomainWarp, terrainWidth, terrainHeight uint32, m float32) *LayeredTerrain {
+	terrain := &LayeredTerrain{
+		layers: append([]TerrainLayer(nil), layers...),
+		warp:   warp,
+		m:      m,
+	}
+	for i, layer := range terrain.layers {
+		if layer.blend == BlendLerp && (layer.mask < 0 || layer.mask >= len(layers)) {
+			terrain.layers[i].mask = -1
+		}
+		xBounds, yBounds := terrain.layers[i].source.Bounds()
+		terrain.layers[i].grid = NewSamplingGrid(xBounds, yBounds, terrainWidth, terrainHeight)
+	}
+	// A terrain without layers has no grid to place its vertices on
+	var grid SamplingGrid
+	if len(terrain.layers) > 0 {
+		grid = terrain.layers[0].grid
+	}
+	terrain.init(grid, terrainWidth, terrainHeight, terrain.HeightAt)
+	return terrain
+}
+
+// The number of layers whose values HeightAt keeps on the stack, terrains with more layers allocate them
+const stackLayers = 16
+
+/*
+ * Calculates the height of the terrain at a column and row of its grid, taking the current displacement into account.
+ * It does not change the terrain, so it can be called from several goroutines at once.
+ * @param col The column of the vertex in the grid
+ * @param row The row of the vertex in the grid
+ */
+func (terrain *LayeredTerrain) HeightAt(col, row int) float32 {
+	col, row = col+terrain.xDisp, row+terrain.yDisp
+	grid := terrain.grid
+	x, y := grid.X(col), grid.Y(row)
+	wx, wy := terrain.warp.Apply(x, y)
+
+	// The value of every layer is kept to look up the masks of BlendLerp layers
+	var stack [stackLayers]float32
+	values := stack[:]
+	if len(terrain.layers) > stackLayers {
+		values = make([]float32, len(terrain.layers))
+	}
+	for i, layer := range terrain.layers {
+		dx, dy := grid.scaleTo(layer.grid, wx-x, wy-y)
+		values[i] = layer.value(col, row, dx, dy)
+	}
+	height := float32(0)
+	for i, layer := range terrain.layers {
+		mask := float32(0)
+		if layer.blend == BlendLerp && layer.mask >= 0 {
+			mask = values[layer.mask]
+		}
+		height = layer.blend.Apply(height, values[i], mask)
+	}
+	return height * terrain.m
+}
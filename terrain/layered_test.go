@@ -0,0 +1,54 @@
+package terrain
+
+import (
+	"sync"
+	"testing"
+)
+
+// Heights looked up from several goroutines at once match the heights the terrain generated, lerp masks included
+func TestLayeredHeightAtConcurrent(t *testing.T) {
+	layers := []TerrainLayer{
+		NewTerrainLayer(NewFractalBoard(5, 5, 43, 1, 2, 0.5), 1, 0, BlendAdd, 0),
+		NewTerrainLayer(NewFractalBoard(9, 9, 97, 3, 2, 0.5), 1, 0.5, BlendNone, 0),
+		NewTerrainLayer(NewFractalBoard(27, 27, 163, 2, 2, 0.5), 0.3, 0, BlendLerp, 1),
+	}
+	terrain := NewLayeredTerrain(layers, DomainWarp{}, 65, 65, 1)
+	field := terrain.HeightField()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for row := g; row < int(field.Height()); row += 8 {
+				for col := 0; col < int(field.Width()); col++ {
+					if h := terrain.HeightAt(col, row); h != field.At(col, row) {
+						t.Errorf("height at (%d, %d) is %v, generated %v", col, row, h, field.At(col, row))
+						return
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+// A lerp layer whose mask is not one of the layers interpolates by 0 instead of indexing past the layers
+func TestLayeredMaskOutOfRange(t *testing.T) {
+	base := NewTerrainLayer(NewFractalBoard(5, 5, 43, 1, 2, 0.5), 1, 0, BlendAdd, 0)
+	want := NewLayeredTerrain([]TerrainLayer{base}, DomainWarp{}, 17, 17, 1).HeightField()
+	for _, mask := range []int{-3, 2, 100} {
+		lerp := NewTerrainLayer(NewFractalBoard(9, 9, 97, 1, 2, 0.5), 1, 0.5, BlendLerp, mask)
+		layers := []TerrainLayer{base, lerp}
+		got := NewLayeredTerrain(layers, DomainWarp{}, 17, 17, 1).HeightField()
+		for i, h := range got.Heights() {
+			if h != want.Heights()[i] {
+				t.Errorf("mask %d: height %d is %v, expected %v from the layer beneath the lerp layer", mask, i, h, want.Heights()[i])
+				break
+			}
+		}
+		if layers[1].mask != mask {
+			t.Errorf("mask %d: the layer passed to NewLayeredTerrain was changed to mask %d", mask, layers[1].mask)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package terrain
+
+import (
+	"math"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//============================================Mesh============================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// A mesh is the triangulated surface of a height field, in the world coordinates of the field with z up. It does not
+// depend on any graphics library, so exporters and renderers all triangulate a terrain the same way.
+type Mesh struct {
+	// The x, y and z position of every vertex, one vertex for every (column, row) of the field in row major order
+	Positions []float32
+	// The x, y and z unit normal of every vertex
+	Normals []float32
+	// The u and v texture coordinate of every vertex, from (0, 0) at the lower corner of the field to (1, 1) at the upper corner
+	UVs []float32
+	// Three vertex indices for every triangle, wound counter-clockwise when viewed from +z
+	Indices []uint32
+}
+
+/*
+ * Triangulates a height field. Each cell is split along the diagonal from its lower left to its upper right vertex.
+ * The normal of a vertex is found from the slope between the vertices on either side of it, or between the vertex
+ * and its only neighbour along the edges of the field.
+ * @param field The height field to triangulate
+ */
+func NewMesh(field *HeightField) *Mesh {
+	return NewRegionMesh(field, 0, 0, field.Width(), field.Height())
+}
+
+/*
+ * Triangulates a rectangular region of a height field, see NewMesh. The normals along the edges of the region are found
+ * from the vertices of the field just outside of it, so the meshes of neighbouring regions shade without seams. The
+ * texture coordinates span the region.
+ * @param field The height field to triangulate
+ * @param col The first column of the region
+ * @param row The first row of the region
+ * @param width The number of columns of the region
+ * @param height The number of rows of the region
+ */
+func NewRegionMesh(field *HeightField, col, row int, width, height uint32) *Mesh {
+	vertices := int(width) * int(height)
+	mesh := &Mesh{
+		Positions: make([]float32, 0, vertices*3),
+		Normals:   make([]float32, 0, vertices*3),
+		UVs:       make([]float32, 0, vertices*2),
+	}
+	if width > 1 && height > 1 {
+		mesh.Indices = make([]uint32, 0, 6*(width-1)*(height-1))
+	}
+
+	spacingX, spacingY := field.Spacing()
+	for j := 0; j < int(height); j++ {
+		for i := 0; i < int(width); i++ {
+			c, r := col+i, row+j
+			mesh.Positions = append(mesh.Positions, float32(field.X(c)), float32(field.Y(r)), float32(field.Float64At(c, r)))
+			dzdx := slope(field.Float64At(c-1, r), field.Float64At(c+1, r), spacingX, c, int(field.Width()))
+			dzdy := slope(field.Float64At(c, r-1), field.Float64At(c, r+1), spacingY, r, int(field.Height()))
+			length := math.Sqrt(dzdx*dzdx + dzdy*dzdy + 1)
+			mesh.Normals = append(mesh.Normals, float32(-dzdx/length), float32(-dzdy/length), float32(1/length))
+			mesh.UVs = append(mesh.UVs, texCoordinate(i, int(width)), texCoordinate(j, int(height)))
+		}
+	}
+
+	for j := uint32(0); j+1 < height; j++ {
+		for i := uint32(0); i+1 < width; i++ {
+			i00 := j*width + i
+			i10 := j*width + i + 1
+			i01 := (j+1)*width + i
+			i11 := (j+1)*width + i + 1
+			mesh.Indices = append(mesh.Indices, i00, i10, i11, i00, i11, i01)
+		}
+	}
+	return mesh
+}
+
+// The number of vertices of the mesh
+func (mesh *Mesh) VertexCount() int {
+	return len(mesh.Positions) / 3
+}
+
+// The number of triangles of the mesh
+func (mesh *Mesh) TriangleCount() int {
+	return len(mesh.Indices) / 3
+}
+
+/*
+ * The slope at a vertex from the heights of the vertices before and after it, which are the vertex itself along the edges
+ * @param before The height of the vertex before, clamped to the field
+ * @param after The height of the vertex after, clamped to the field
+ * @param spacing The distance between neighbouring vertices
+ * @param i The index of the vertex
+ * @param count The number of vertices
+ */
+func slope(before, after, spacing float64, i, count int) float64 {
+	if spacing == 0 || count < 2 {
+		return 0
+	}
+	// Only one of the neighbours is inside the field along its edges
+	steps := float64(2)
+	if i == 0 || i == count-1 {
+		steps = 1
+	}
+	return (after - before) / (steps * spacing)
+}
+
+/*
+ * Spreads the texture coordinates of count vertices evenly from 0 to 1
+ * @param i The index of the vertex
+ * @param count The number of vertices
+ */
+func texCoordinate(i, count int) float32 {
+	if count < 2 {
+		return 0
+	}
+	return float32(i) / float32(count-1)
+}
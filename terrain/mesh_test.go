@@ -0,0 +1,78 @@
+package terrain
+
+import (
+	"math"
+	"testing"
+)
+
+// A mesh has a vertex for every vertex of the field and two triangles for every cell
+func TestMeshCounts(t *testing.T) {
+	for _, gradients := range testGradients {
+		for _, width := range testSizes {
+			for _, height := range testSizes {
+				board := NewGradientBoard(gradients, gradients, 43)
+				mesh := NewMesh(NewSimpleTerrain(board, DomainWarp{}, width, height, 1).HeightField())
+				w, h := int(width), int(height)
+				if mesh.VertexCount() != w*h {
+					t.Errorf("%d gradients, %dx%d: %d vertices, expected %d", gradients, w, h, mesh.VertexCount(), w*h)
+				}
+				if mesh.TriangleCount() != 2*(w-1)*(h-1) {
+					t.Errorf("%d gradients, %dx%d: %d triangles, expected %d", gradients, w, h, mesh.TriangleCount(), 2*(w-1)*(h-1))
+				}
+				if len(mesh.Normals) != 3*w*h || len(mesh.UVs) != 2*w*h {
+					t.Errorf("%d gradients, %dx%d: %d normals and %d uvs", gradients, w, h, len(mesh.Normals)/3, len(mesh.UVs)/2)
+				}
+				for _, index := range mesh.Indices {
+					if int(index) >= w*h {
+						t.Fatalf("%d gradients, %dx%d: index %d is not a vertex", gradients, w, h, index)
+					}
+				}
+			}
+		}
+	}
+}
+
+// The normals of a plane z = ax + by + c are all (-a, -b, 1) / |(-a, -b, 1)|, along its edges as well as inside it
+func TestMeshNormalsOfPlane(t *testing.T) {
+	const a, b = 0.75, -2.0
+	extent := Extent{MinX: -3, MinY: 2, MaxX: 5, MaxY: 5}
+	field := NewHeightField(9, 7, extent)
+	for row := 0; row < 7; row++ {
+		for col := 0; col < 9; col++ {
+			field.Set(col, row, float32(a*field.X(col)+b*field.Y(row)+1))
+		}
+	}
+	length := math.Sqrt(a*a + b*b + 1)
+	want := []float64{-a / length, -b / length, 1 / length}
+	mesh := NewMesh(field)
+	for i := 0; i < mesh.VertexCount(); i++ {
+		for k := 0; k < 3; k++ {
+			if math.Abs(float64(mesh.Normals[i*3+k])-want[k]) > 1e-5 {
+				t.Fatalf("vertex %d has the normal %v, expected %v", i, mesh.Normals[i*3:i*3+3], want)
+			}
+		}
+	}
+}
+
+// The normals of a moved terrain are found from its new heights, and are still unit length
+func TestMeshNormalsAfterMove(t *testing.T) {
+	surface := NewSimpleTerrain(NewGradientBoard(5, 5, 43), DomainWarp{}, 17, 13, 1.5)
+	before := NewMesh(surface.HeightField()).Normals
+	surface.MoveRight(3)
+	surface.MoveUp(2)
+	after := NewMesh(surface.HeightField()).Normals
+
+	changed := 0
+	for i := 0; i < len(after); i += 3 {
+		n := after[i : i+3]
+		if length := math.Sqrt(float64(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])); math.Abs(length-1) > 1e-5 {
+			t.Errorf("vertex %d has the normal %v of length %v", i/3, n, length)
+		}
+		if n[0] != before[i] || n[1] != before[i+1] || n[2] != before[i+2] {
+			changed++
+		}
+	}
+	if changed == 0 {
+		t.Error("the normals did not change when the terrain moved")
+	}
+}
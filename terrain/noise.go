@@ -0,0 +1,106 @@
+package terrain
+
+import (
+	"math"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//========================================NoiseBackend========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// A noise backend is the gradient noise algorithm a GradientBoard evaluates. The backends of this package hash their
+// lattice points with the board's calculateGradient, so their output only depends on the board's seed and is the same on
+// every run. Other backends can be given to UseBackend by implementing Evaluate.
+type NoiseBackend interface {
+	/*
+	 * Returns the noise of a board at an x and y position together with its partial derivatives (dh/dx, dh/dy)
+	 * @param board The gradient board whose seed and bounds are used
+	 * @param x The x position at which we would like to have a height
+	 * @param y The y position at which we would like to have a height
+	 */
+	Evaluate(board GradientBoard, x, y float32) (float32, float32, float32)
+}
+
+// The noise backends as they are written in map files. A map that does not name a backend uses perlin noise.
+var noiseBackends = map[string]NoiseBackend{
+	"":        PerlinBackend{},
+	"perlin":  PerlinBackend{},
+	"simplex": SimplexBackend{},
+	"worley":  WorleyBackend{},
+}
+
+/*
+ * Finds the noise backend with a name from a map file
+ * @param name The name of the noise backend, an empty name is the default perlin backend
+ */
+func ParseNoiseBackend(name string) (NoiseBackend, bool) {
+	backend, ok := noiseBackends[name]
+	return backend, ok
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=======================================PerlinBackend========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// Classic grid perlin noise with the quintic fade, see GradientBoard.perlinNoise
+type PerlinBackend struct{}
+
+func (PerlinBackend) Evaluate(board GradientBoard, x, y float32) (float32, float32, float32) {
+	return board.perlinNoiseDerivatives(x, y)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=======================================SimplexBackend=======================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// 2D simplex noise. The plane is split into triangles rather than squares, so the noise does not show the axis aligned
+// artifacts of perlin noise. Its output is scaled to roughly (-1, 1). The lattice of simplex noise is skewed, so it does
+// not repeat along x and y even when the board has a period.
+type SimplexBackend struct{}
+
+// The factors that skew the plane onto the simplex lattice and unskew it back
+var (
+	simplexSkew   = float32(0.5 * (math.Sqrt(3) - 1))
+	simplexUnskew = float32((3 - math.Sqrt(3)) / 6)
+)
+
+/*
+ * Simplex noise algorithm will sum the contributions of the three corners of the simplex containing the x and y position.
+ * Each corner contributes (0.5 - d^2)^4 times the dot product of its gradient with the offset d from it.
+ */
+func (SimplexBackend) Evaluate(board GradientBoard, x, y float32) (float32, float32, float32) {
+	// Find the lattice cell of the skewed plane that contains the position
+	s := (x + y) * simplexSkew
+	i := int32(math.Floor(float64(x + s)))
+	j := int32(math.Floor(float64(y + s)))
+	t := float32(i+j) * simplexUnskew
+	x0 := x - (float32(i) - t)
+	y0 := y - (float32(j) - t)
+
+	// The middle corner of the simplex depends on which half of the cell the position is in
+	i1, j1 := int32(0), int32(1)
+	if x0 > y0 {
+		i1, j1 = 1, 0
+	}
+
+	corners := [3][2]float32{
+		{x0, y0},
+		{x0 - float32(i1) + simplexUnskew, y0 - float32(j1) + simplexUnskew},
+		{x0 - 1 + 2*simplexUnskew, y0 - 1 + 2*simplexUnskew},
+	}
+	lattice := [3][2]int32{{i, j}, {i + i1, j + j1}, {i + 1, j + 1}}
+
+	n, dndx, dndy := float32(0), float32(0), float32(0)
+	for c, corner := range corners {
+		cx, cy := corner[0], corner[1]
+		falloff := 0.5 - cx*cx - cy*cy
+		if falloff <= 0 {
+			continue
+		}
+		gx, gy := toXY(board.calculateGradient(lattice[c][0], lattice[c][1]), 1, 1)
+		dot := gx*cx + gy*cy
+		falloff2 := falloff * falloff
+		falloff4 := falloff2 * falloff2
+		n += falloff4 * dot
+		dndx += falloff4*gx - 8*falloff2*falloff*cx*dot
+		dndy += falloff4*gy - 8*falloff2*falloff*cy*dot
+	}
+	return 70 * n, 70 * dndx, 70 * dndy
+}
@@ -0,0 +1,90 @@
+package terrain
+
+import (
+	"math"
+	"testing"
+)
+
+// The derivatives of the perlin and simplex backends match a central difference of their noise
+func TestNoiseDerivatives(t *testing.T) {
+	const h = 1e-3
+	const tolerance = 2e-3
+	backends := map[string]NoiseBackend{"perlin": PerlinBackend{}, "simplex": SimplexBackend{}}
+	for name, backend := range backends {
+		for _, seed := range []int32{1, 43, 97, 7919, -31337} {
+			board := NewGradientBoard(27, 27, seed)
+			board.UseBackend(backend)
+			for i := 0; i < 400; i++ {
+				// Spread the positions evenly over the board with the fractional parts of two irrational multiples
+				x := float32(-13 + 26*math.Mod(float64(i)*0.6180339887, 1))
+				y := float32(-13 + 26*math.Mod(float64(i)*0.7548776662+0.31, 1))
+				_, dx, dy := board.NoiseDerivatives(x, y)
+				ndx := (float64(board.Noise(x+h, y)) - float64(board.Noise(x-h, y))) / (2 * h)
+				ndy := (float64(board.Noise(x, y+h)) - float64(board.Noise(x, y-h))) / (2 * h)
+				if math.Abs(float64(dx)-ndx) > tolerance || math.Abs(float64(dy)-ndy) > tolerance {
+					t.Errorf("%s, seed %d, (%v, %v): derivatives (%v, %v), numerically (%v, %v)", name, seed, x, y, dx, dy, ndx, ndy)
+				}
+			}
+		}
+	}
+}
+
+// A backend as it would be written outside of this package, a plane tilted along x
+type tiltBackend struct{}
+
+func (tiltBackend) Evaluate(board GradientBoard, x, y float32) (float32, float32, float32) {
+	xBounds, _ := board.Bounds()
+	return x / float32(xBounds.Upper()), 1 / float32(xBounds.Upper()), 0
+}
+
+// Boards evaluate the backends given to UseBackend, and report the bounds they span
+func TestCustomBackend(t *testing.T) {
+	board := NewGradientBoard(8, 6, 43)
+	xBounds, yBounds := board.Bounds()
+	if xBounds.Lower() != -4 || xBounds.Upper() != 4 || yBounds.Lower() != -3 || yBounds.Upper() != 3 {
+		t.Fatalf("the bounds are [%d, %d] x [%d, %d], expected [-4, 4] x [-3, 3]",
+			xBounds.Lower(), xBounds.Upper(), yBounds.Lower(), yBounds.Upper())
+	}
+	board.UseBackend(tiltBackend{})
+	if h := board.Noise(2, 1); h != 0.5 {
+		t.Errorf("the noise at (2, 1) is %v, expected 0.5 from the custom backend", h)
+	}
+	if _, dx, dy := board.NoiseDerivatives(2, 1); dx != 0.25 || dy != 0 {
+		t.Errorf("the derivatives at (2, 1) are (%v, %v), expected (0.25, 0)", dx, dy)
+	}
+}
+
+// Simplex noise only depends on its seed, a different seed gives different noise, and it stays within (-1, 1)
+func TestSimplexBackendSeeds(t *testing.T) {
+	const samples = 2000
+	noise := func(seed int32) []float32 {
+		board := NewGradientBoard(27, 27, seed)
+		board.UseBackend(SimplexBackend{})
+		heights := make([]float32, samples)
+		for i := range heights {
+			x := float32(-13 + 26*math.Mod(float64(i)*0.6180339887, 1))
+			y := float32(-13 + 26*math.Mod(float64(i)*0.7548776662+0.31, 1))
+			heights[i] = board.Noise(x, y)
+		}
+		return heights
+	}
+
+	for _, seed := range []int32{1, 43, 97, 7919, -31337} {
+		first, second, other := noise(seed), noise(seed), noise(seed+1)
+		differ := 0
+		for i := range first {
+			if first[i] != second[i] {
+				t.Fatalf("seed %d: sample %d is %v and then %v", seed, i, first[i], second[i])
+			}
+			if h := first[i]; !(h > -1 && h < 1) {
+				t.Errorf("seed %d: sample %d is %v, outside (-1, 1)", seed, i, h)
+			}
+			if first[i] != other[i] {
+				differ++
+			}
+		}
+		if differ < samples/2 {
+			t.Errorf("seeds %d and %d agree on %d of %d samples", seed, seed+1, samples-differ, samples)
+		}
+	}
+}
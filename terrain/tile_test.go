@@ -0,0 +1,58 @@
+package terrain
+
+import (
+	"math"
+	"testing"
+)
+
+// Periodic terrains of the shape of maps/tile_test.json, every one of them needs to tile
+func periodicTerrains() map[string]Terrain {
+	simple := NewGradientBoard(9, 9, 43)
+	simple.Tile()
+
+	fractal := NewFractalBoard(9, 9, 97, 5, 2, 0.5)
+	fractal.Tile()
+
+	ridged := NewFractalBoard(5, 5, 163, 3, 2, 0.5)
+	ridged.UseStyle(FractalRidged, 1, 2, 2)
+	ridged.Tile()
+	worley := NewFractalBoard(7, 7, 71, 2, 3, 0.5)
+	worley.UseBackend(NewWorleyBackend(WorleyF2MinusF1, MetricEuclidean))
+	worley.Tile()
+	layers := []TerrainLayer{
+		NewTerrainLayer(fractal, 1, 0, BlendAdd, 0),
+		NewTerrainLayer(ridged, 0.5, 0, BlendNone, 0),
+		NewTerrainLayer(worley, 0.3, 0, BlendLerp, 1),
+	}
+
+	warp := NewDomainWarp(11, 0.6, 1, 2)
+	xBounds, yBounds := fractal.Bounds()
+	warp.UsePeriod(uint32(xBounds.Size()), uint32(yBounds.Size()))
+
+	return map[string]Terrain{
+		"simple":  NewSimpleTerrain(simple, DomainWarp{}, 65, 49, 1),
+		"fractal": NewFractalTerrain(fractal, DomainWarp{}, 65, 49, 1),
+		"layered": NewLayeredTerrain(layers, DomainWarp{}, 65, 49, 1),
+		"warped":  NewFractalTerrain(fractal, warp, 65, 49, 1),
+	}
+}
+
+// The opposite edges of a periodic terrain have the same heights, so its exported heightmaps tile seamlessly
+func TestPeriodicEdges(t *testing.T) {
+	// The warped positions on opposite edges only differ by float32 rounding, see DomainWarp.UsePeriod
+	const tolerance = 1e-4
+	for name, terrain := range periodicTerrains() {
+		field := terrain.HeightField()
+		w, h := int(field.Width()), int(field.Height())
+		for r := 0; r < h; r++ {
+			if d := math.Abs(float64(field.At(0, r) - field.At(w-1, r))); d > tolerance {
+				t.Errorf("%s: row %d differs by %v between the left and right edges", name, r, d)
+			}
+		}
+		for c := 0; c < w; c++ {
+			if d := math.Abs(float64(field.At(c, 0) - field.At(c, h-1))); d > tolerance {
+				t.Errorf("%s: column %d differs by %v between the bottom and top edges", name, c, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package terrain
+
+import (
+	"math"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=========================================DomainWarp=========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// A domain warp distorts the coordinates a terrain samples its boards at by the noise of two other gradient boards,
+// one moving positions along x and one along y. Iterating the warp feeds the warped position back into the warp boards,
+// which folds the distortion into itself and produces swirling, eroded looking shapes.
+// The zero value of a DomainWarp leaves every position where it is.
+type DomainWarp struct {
+	// The boards whose noise moves positions in the x and y directions
+	xBoard GradientBoard
+	yBoard GradientBoard
+	// The distance, in board coordinates, that noise of 1 moves a position by
+	strength float32
+	// The frequency of the warp boards relative to the boards of the terrain
+	frequency float32
+	// The number of times the warp is applied, each time sampling the warp boards at the previously warped position
+	iterations uint32
+}
+
+/*
+ * Creates a domain warp with a pair of warp boards
+ * @param seed The seed that the seeds of the x and y warp boards are derived from
+ * @param strength The distance, in board coordinates, that noise of 1 moves a position by
+ * @param frequency The frequency of the warp boards relative to the boards of the terrain
+ * @param iterations The number of times the warp is applied, 0 turns the warp off
+ */
+func NewDomainWarp(seed int32, strength, frequency float32, iterations uint32) DomainWarp {
+	// The warp boards are sampled at terrain coordinates, so they do not need bounds of their own
+	return DomainWarp{
+		xBoard:     NewGradientBoard(0, 0, octaveSeed(seed, 0)),
+		yBoard:     NewGradientBoard(0, 0, octaveSeed(seed, 1)),
+		strength:   strength,
+		frequency:  frequency,
+		iterations: iterations,
+	}
+}
+
+/*
+ * Changes the gradient noise algorithm evaluated by the warp boards, they use the PerlinBackend unless they are changed
+ * @param backend The noise backend the warp boards will evaluate
+ */
+func (warp *DomainWarp) UseBackend(backend NoiseBackend) {
+	warp.xBoard.UseBackend(backend)
+	warp.yBoard.UseBackend(backend)
+}
+
+/*
+ * Makes the warp repeat with the terrain it distorts, so warping a periodic terrain keeps it periodic. The warp boards are
+ * sampled at frequency times the terrain's coordinates, so they only repeat with the terrain when the period times the
+ * frequency is a whole number of cells. The warped positions on opposite edges then only differ by float32 rounding.
+ * @param xPeriod The number of cells after which the terrain repeats in the x direction
+ * @param yPeriod The number of cells after which the terrain repeats in the y direction
+ */
+func (warp *DomainWarp) UsePeriod(xPeriod, yPeriod uint32) {
+	xWarpPeriod := uint32(math.Round(float64(xPeriod) * float64(warp.frequency)))
+	yWarpPeriod := uint32(math.Round(float64(yPeriod) * float64(warp.frequency)))
+	warp.xBoard.UsePeriod(xWarpPeriod, yWarpPeriod)
+	warp.yBoard.UsePeriod(xWarpPeriod, yWarpPeriod)
+}
+
+/*
+ * Moves a position by the noise of the warp boards, applying the warp once for every iteration
+ * @param x The x position to warp, in board coordinates
+ * @param y The y position to warp, in board coordinates
+ */
+func (warp DomainWarp) Apply(x, y float32) (float32, float32) {
+	if warp.strength == 0 {
+		return x, y
+	}
+	wx, wy := x, y
+	for i := uint32(0); i < warp.iterations; i++ {
+		dx := warp.xBoard.Noise(wx*warp.frequency, wy*warp.frequency)
+		dy := warp.yBoard.Noise(wx*warp.frequency, wy*warp.frequency)
+		wx, wy = x+warp.strength*dx, y+warp.strength*dy
+	}
+	return wx, wy
+}
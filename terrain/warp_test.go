@@ -0,0 +1,70 @@
+package terrain
+
+import "testing"
+
+// The positions the tests of the domain warp move, spread over a few gradient cells and off the gradients themselves
+func warpPositions() [][2]float32 {
+	var positions [][2]float32
+	for y := float32(-2.3); y < 2.5; y += 0.37 {
+		for x := float32(-2.1); x < 2.5; x += 0.41 {
+			positions = append(positions, [2]float32{x, y})
+		}
+	}
+	return positions
+}
+
+// A warp without strength, like the zero value, leaves every position where it is
+func TestDomainWarpZeroStrength(t *testing.T) {
+	for _, warp := range []DomainWarp{{}, NewDomainWarp(11, 0, 0.5, 3)} {
+		for _, p := range warpPositions() {
+			if x, y := warp.Apply(p[0], p[1]); x != p[0] || y != p[1] {
+				t.Fatalf("a warp of strength 0 moved %v to (%v, %v)", p, x, y)
+			}
+		}
+	}
+}
+
+// Warps of the same seed move positions the same way, and warps of different seeds move them differently
+func TestDomainWarpSeed(t *testing.T) {
+	a, b, other := NewDomainWarp(11, 0.6, 0.5, 2), NewDomainWarp(11, 0.6, 0.5, 2), NewDomainWarp(12, 0.6, 0.5, 2)
+	moved, differ := 0, 0
+	for _, p := range warpPositions() {
+		ax, ay := a.Apply(p[0], p[1])
+		bx, by := b.Apply(p[0], p[1])
+		ox, oy := other.Apply(p[0], p[1])
+		if ax != bx || ay != by {
+			t.Fatalf("warps of the same seed moved %v to (%v, %v) and (%v, %v)", p, ax, ay, bx, by)
+		}
+		if ax != p[0] || ay != p[1] {
+			moved++
+		}
+		if ax != ox || ay != oy {
+			differ++
+		}
+	}
+	if positions := len(warpPositions()); moved < positions/2 || differ < positions/2 {
+		t.Errorf("the warp moved %d and a warp of another seed moved %d of %d positions differently", moved, differ, positions)
+	}
+}
+
+// Iterating a warp samples the warp boards at the warped position, which moves positions differently than a single warp
+func TestDomainWarpIterations(t *testing.T) {
+	once, twice := NewDomainWarp(11, 0.6, 0.5, 1), NewDomainWarp(11, 0.6, 0.5, 2)
+	differ := 0
+	for _, p := range warpPositions() {
+		x1, y1 := once.Apply(p[0], p[1])
+		x2, y2 := twice.Apply(p[0], p[1])
+		if x1 != x2 || y1 != y2 {
+			differ++
+		}
+		// The second iteration warps the original position by the noise at the position of the first
+		dx := once.xBoard.Noise(x1*once.frequency, y1*once.frequency)
+		dy := once.yBoard.Noise(x1*once.frequency, y1*once.frequency)
+		if x2 != p[0]+once.strength*dx || y2 != p[1]+once.strength*dy {
+			t.Fatalf("two iterations moved %v to (%v, %v), expected (%v, %v)", p, x2, y2, p[0]+once.strength*dx, p[1]+once.strength*dy)
+		}
+	}
+	if differ < len(warpPositions())/2 {
+		t.Errorf("two iterations moved only %d of %d positions differently than one", differ, len(warpPositions()))
+	}
+}
@@ -0,0 +1,157 @@
+package terrain
+
+import (
+	"math"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=======================================DistanceMetric=======================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The metric used to measure the distance from a position to the feature points of worley noise
+type DistanceMetric uint8
+
+const (
+	// Straight line distance, round cells
+	MetricEuclidean DistanceMetric = iota
+	// Sum of the axis distances, diamond shaped cells
+	MetricManhattan
+	// Largest of the axis distances, square cells
+	MetricChebyshev
+)
+
+// The distance metrics as they are written in map files
+var metricNames = map[string]DistanceMetric{
+	"euclidean": MetricEuclidean,
+	"manhattan": MetricManhattan,
+	"chebyshev": MetricChebyshev,
+}
+
+/*
+ * Finds the distance metric with a name from a map file
+ * @param name The name of the distance metric
+ */
+func ParseDistanceMetric(name string) (DistanceMetric, bool) {
+	metric, ok := metricNames[name]
+	return metric, ok
+}
+
+/*
+ * Measures the length of an offset and returns it together with its partial derivatives with respect to the offset
+ * @param dx The x component of the offset
+ * @param dy The y component of the offset
+ */
+func (metric DistanceMetric) distance(dx, dy float32) (float32, float32, float32) {
+	switch metric {
+	case MetricManhattan:
+		return abs(dx) + abs(dy), sign(dx), sign(dy)
+	case MetricChebyshev:
+		if abs(dx) >= abs(dy) {
+			return abs(dx), sign(dx), 0
+		}
+		return abs(dy), 0, sign(dy)
+	}
+	d := float32(math.Sqrt(float64(dx*dx + dy*dy)))
+	if d == 0 {
+		return 0, 0, 0
+	}
+	return d, dx / d, dy / d
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=======================================WorleyDistance=======================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// The distance returned by worley noise
+type WorleyDistance uint8
+
+const (
+	// The distance to the closest feature point, rounded cells like mesas
+	WorleyF1 WorleyDistance = iota
+	// The distance to the second closest feature point
+	WorleyF2
+	// The difference between the second closest and the closest, ridges along the cell borders like cracks and plate boundaries
+	WorleyF2MinusF1
+)
+
+// The worley distances as they are written in map files
+var worleyDistanceNames = map[string]WorleyDistance{
+	"f1":    WorleyF1,
+	"f2":    WorleyF2,
+	"f2-f1": WorleyF2MinusF1,
+}
+
+/*
+ * Finds the worley distance with a name from a map file
+ * @param name The name of the worley distance
+ */
+func ParseWorleyDistance(name string) (WorleyDistance, bool) {
+	distance, ok := worleyDistanceNames[name]
+	return distance, ok
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////
+//=======================================WorleyBackend========================================//
+////////////////////////////////////////////////////////////////////////////////////////////////
+// Cellular (worley) noise. Every cell of the board's integer lattice holds one feature point placed by hashing the cell
+// with the board's seed, and the noise at a position is a distance to the feature points around it.
+type WorleyBackend struct {
+	// The feature point distance returned by the noise
+	distance WorleyDistance
+	// The metric the distances are measured with
+	metric DistanceMetric
+}
+
+/*
+ * Creates a worley noise backend
+ * @param distance The feature point distance returned by the noise
+ * @param metric The metric the distances are measured with
+ */
+func NewWorleyBackend(distance WorleyDistance, metric DistanceMetric) WorleyBackend {
+	return WorleyBackend{distance: distance, metric: metric}
+}
+
+/*
+ * Calculate feature point is a GradientBoard method that will take in integer coordinate points and determine where in that
+ * cell of the board its worley feature point is. It takes the board's hash of the coordinates, as calculateGradient does,
+ * and returns the offset of the point from the cell's lower corner, in [0, 1) on both axes.
+ * @param x the x coordinate of the cell
+ * @param y the y coordinate of the cell
+ */
+func (board GradientBoard) calculateFeaturePoint(x, y int32) (float32, float32) {
+	// The hash is mixed again so that the feature point does not follow the gradient angle of the same cell
+	h := mix(uint32(board.hash(x, y)))
+	return float32(h&0xffff) / 65536, float32(h>>16) / 65536
+}
+
+/*
+ * Worley noise algorithm will find the closest and second closest feature points in the 5x5 cells around the position,
+ * and return the worley distance of the backend with its partial derivatives. The 3x3 cells around the position always
+ * hold two feature points within a distance of 2 under every metric, and the cells outside of the 5x5 are at least 2 away,
+ * so F1 and F2 are exact.
+ */
+func (worley WorleyBackend) Evaluate(board GradientBoard, x, y float32) (float32, float32, float32) {
+	cx := int32(math.Floor(float64(x)))
+	cy := int32(math.Floor(float64(y)))
+
+	f1, f1dx, f1dy := float32(math.Inf(1)), float32(0), float32(0)
+	f2, f2dx, f2dy := float32(math.Inf(1)), float32(0), float32(0)
+	for j := cy - 2; j <= cy+2; j++ {
+		for i := cx - 2; i <= cx+2; i++ {
+			px, py := board.calculateFeaturePoint(i, j)
+			d, ddx, ddy := worley.metric.distance(x-(float32(i)+px), y-(float32(j)+py))
+			if d < f1 {
+				f2, f2dx, f2dy = f1, f1dx, f1dy
+				f1, f1dx, f1dy = d, ddx, ddy
+			} else if d < f2 {
+				f2, f2dx, f2dy = d, ddx, ddy
+			}
+		}
+	}
+
+	switch worley.distance {
+	case WorleyF2:
+		return f2, f2dx, f2dy
+	case WorleyF2MinusF1:
+		return f2 - f1, f2dx - f1dx, f2dy - f1dy
+	}
+	return f1, f1dx, f1dy
+}
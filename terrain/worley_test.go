@@ -0,0 +1,111 @@
+package terrain
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+// The distances to the feature points of the 7x7 cells around a position, closest first
+func bruteForceWorley(board GradientBoard, metric DistanceMetric, x, y float32) []float32 {
+	cx := int32(math.Floor(float64(x)))
+	cy := int32(math.Floor(float64(y)))
+	var distances []float32
+	for j := cy - 3; j <= cy+3; j++ {
+		for i := cx - 3; i <= cx+3; i++ {
+			px, py := board.calculateFeaturePoint(i, j)
+			d, _, _ := metric.distance(x-(float32(i)+px), y-(float32(j)+py))
+			distances = append(distances, d)
+		}
+	}
+	sort.Slice(distances, func(a, b int) bool { return distances[a] < distances[b] })
+	return distances
+}
+
+// F1, F2 and F2-F1 match a brute force search of the 7x7 cells around the position under every metric
+func TestWorleyBruteForce(t *testing.T) {
+	metrics := map[string]DistanceMetric{"euclidean": MetricEuclidean, "manhattan": MetricManhattan, "chebyshev": MetricChebyshev}
+	distances := map[string]WorleyDistance{"f1": WorleyF1, "f2": WorleyF2, "f2-f1": WorleyF2MinusF1}
+	for metricName, metric := range metrics {
+		for distanceName, distance := range distances {
+			for _, seed := range []int32{1, 43, 97, -31337} {
+				board := NewGradientBoard(27, 27, seed)
+				worley := NewWorleyBackend(distance, metric)
+				for i := 0; i < 500; i++ {
+					// Spread the positions evenly over the board with the fractional parts of two irrational multiples
+					x := float32(-13 + 26*math.Mod(float64(i)*0.6180339887, 1))
+					y := float32(-13 + 26*math.Mod(float64(i)*0.7548776662+0.31, 1))
+					sorted := bruteForceWorley(board, metric, x, y)
+					want := map[WorleyDistance]float32{WorleyF1: sorted[0], WorleyF2: sorted[1], WorleyF2MinusF1: sorted[1] - sorted[0]}[distance]
+					if got, _, _ := worley.Evaluate(board, x, y); got != want {
+						t.Errorf("%s %s, seed %d, (%v, %v): %v, brute force %v", distanceName, metricName, seed, x, y, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+// F1 is never above F2, so F2-F1 is never negative
+func TestWorleyOrder(t *testing.T) {
+	board := NewGradientBoard(27, 27, 43)
+	for _, metric := range []DistanceMetric{MetricEuclidean, MetricManhattan, MetricChebyshev} {
+		for i := 0; i < 500; i++ {
+			x := float32(-13 + 26*math.Mod(float64(i)*0.6180339887, 1))
+			y := float32(-13 + 26*math.Mod(float64(i)*0.7548776662+0.31, 1))
+			f1, _, _ := NewWorleyBackend(WorleyF1, metric).Evaluate(board, x, y)
+			f2, _, _ := NewWorleyBackend(WorleyF2, metric).Evaluate(board, x, y)
+			diff, _, _ := NewWorleyBackend(WorleyF2MinusF1, metric).Evaluate(board, x, y)
+			if f1 > f2 || diff < 0 {
+				t.Errorf("metric %d, (%v, %v): f1 %v, f2 %v, f2-f1 %v", metric, x, y, f1, f2, diff)
+			}
+		}
+	}
+}
+
+// Each metric measures an offset with its own shape: a circle, a diamond and a square
+func TestDistanceMetrics(t *testing.T) {
+	cases := []struct {
+		metric      DistanceMetric
+		d, ddx, ddy float32
+		dx, dy      float32
+	}{
+		{MetricEuclidean, 5, 0.6, -0.8, 3, -4},
+		{MetricManhattan, 7, 1, -1, 3, -4},
+		{MetricChebyshev, 4, 0, -1, 3, -4},
+		{MetricChebyshev, 3, -1, 0, -3, 2},
+		{MetricEuclidean, 0, 0, 0, 0, 0},
+	}
+	for _, c := range cases {
+		d, ddx, ddy := c.metric.distance(c.dx, c.dy)
+		if d != c.d || ddx != c.ddx || ddy != c.ddy {
+			t.Errorf("metric %d of (%v, %v) is %v with derivatives (%v, %v), expected %v with (%v, %v)",
+				c.metric, c.dx, c.dy, d, ddx, ddy, c.d, c.ddx, c.ddy)
+		}
+	}
+}
+
+// Every feature point lies inside its cell, and the feature points only depend on the seed of the board
+func TestWorleyFeaturePoints(t *testing.T) {
+	board := NewGradientBoard(27, 27, 43)
+	same := NewGradientBoard(27, 27, 43)
+	other := NewGradientBoard(27, 27, 97)
+	moved := 0
+	for j := int32(-13); j <= 13; j++ {
+		for i := int32(-13); i <= 13; i++ {
+			px, py := board.calculateFeaturePoint(i, j)
+			if px < 0 || px >= 1 || py < 0 || py >= 1 {
+				t.Errorf("the feature point of cell (%d, %d) is (%v, %v), outside of [0, 1)", i, j, px, py)
+			}
+			if sx, sy := same.calculateFeaturePoint(i, j); sx != px || sy != py {
+				t.Errorf("the feature point of cell (%d, %d) is (%v, %v) and (%v, %v) with the same seed", i, j, px, py, sx, sy)
+			}
+			if ox, oy := other.calculateFeaturePoint(i, j); ox != px || oy != py {
+				moved++
+			}
+		}
+	}
+	if moved == 0 {
+		t.Error("the feature points of seeds 43 and 97 are the same")
+	}
+}
@@ -0,0 +1,157 @@
+//go:build !headless
+
+package main
+
+import (
+	"time"
+
+	"terrain-generation/terrain"
+
+	"github.com/g3n/engine/app"
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/renderer"
+	"github.com/g3n/engine/util/helper"
+	"github.com/g3n/engine/window"
+)
+
+func prepareScene(cam_multipliler uint32) (*app.Application, *core.Node, *camera.Camera) {
+	// Create application and scene
+	a := app.App()
+	scene := core.NewNode()
+
+	// Set the scene to be managed by the gui manager
+	gui.Manager().Set(scene)
+
+	// Create perspective camera
+	cam := camera.New(1)
+	camPosition := float32(cam_multipliler)
+	cam.SetPosition(-camPosition/2, -camPosition*1.7, camPosition*1.7)
+	cam.LookAt(&math32.Vector3{0, 0, -1.2}, &math32.Vector3{0, 0, 1})
+	scene.Add(cam)
+
+	// Set up orbit control for the camera
+	camera.NewOrbitControl(cam)
+
+	// Set up callback to update viewport and camera aspect ratio when the window is resized
+	onResize := func(evname string, ev interface{}) {
+		// Get framebuffer size and update viewport accordingly
+		width, height := a.GetSize()
+		a.Gls().Viewport(0, 0, int32(width), int32(height))
+		// Update the camera's aspect ratio
+		cam.SetAspect(float32(width) / float32(height))
+	}
+	a.Subscribe(window.OnWindowSize, onResize)
+	onResize("", nil)
+
+	return a, scene, cam
+}
+
+func completeScene(a *app.Application, scene *core.Node, mesh *TerrainMesh, cam *camera.Camera) {
+	// Variables to keep track of the current dispacement from the terrain origin
+	xDisp := 0
+	yDisp := 0
+
+	// Label for Y slider
+	sliderYTitle := gui.NewLabel("Y")
+	sliderYTitle.SetPosition(8, 3)
+	//sliderYTitle.SetSize(5.0, 5.0)
+	scene.Add(sliderYTitle)
+
+	// Label for X slider
+	sliderXTitle := gui.NewLabel("X")
+	sliderXTitle.SetPosition(18, 3)
+	//sliderXTitle.SetSize(5.0, 5.0)
+	scene.Add(sliderXTitle)
+
+	// Y Slider for changing the rendered terrain in the Y direction
+	ySlider := gui.NewVScrollBar(8, 570)
+	ySlider.SetPosition(8, 20)
+	ySlider.SetScale(0, 570, 0)
+	ySlider.SetValue(0.5)
+	ySlider.Subscribe(gui.OnChange, func(name string, ev interface{}) {
+		if int(ySlider.Value()*570)-285 > yDisp {
+			mesh.MoveDown(yDisp - (int(ySlider.Value()*570) - 285))
+		} else if int(ySlider.Value()*570)-285 < yDisp {
+			mesh.MoveUp(yDisp - (int(ySlider.Value()*570) - 285))
+		}
+		yDisp = int(ySlider.Value()*570) - 285
+	})
+	scene.Add(ySlider)
+
+	// X Slider for changing the rendered terrain in the X direction
+	xSlider := gui.NewVScrollBar(8, 570)
+	xSlider.SetPosition(18, 20)
+	xSlider.SetScale(0, 570, 0)
+	xSlider.SetValue(0.5)
+	xSlider.Subscribe(gui.OnChange, func(name string, ev interface{}) {
+		if int(xSlider.Value()*570)-285 > xDisp {
+			mesh.MoveLeft(xDisp - (int(xSlider.Value()*570) - 285))
+		} else if int(xSlider.Value()*570)-285 < xDisp {
+			mesh.MoveRight(xDisp - (int(xSlider.Value()*570) - 285))
+		}
+		xDisp = int(xSlider.Value()*570) - 285
+	})
+	scene.Add(xSlider)
+
+	// water plane
+	//waterGeometry := geometry.NewPlane(GRADIENT_WIDTH_B1-1, GRADIENT_HEIGHT_B1-1)
+	//waterColor := material.NewStandard(math32.NewColor("darkblue"))
+	//water := graphic.NewMesh(waterGeometry, waterColor)
+	//waterGeometry.OperateOnVertices(func(vertex *math32.Vector3) bool {
+	//	vertex.Z = -0.2 * M
+	//	return false
+	//})
+	//scene.Add(water)
+
+	// Create and add lights to the scene
+	scene.Add(light.NewAmbient(&math32.Color{1.0, 1.0, 1.0}, 0.5))
+	light := light.NewPoint(&math32.Color{1, 1, 1}, 5)
+	light.SetPositionVec(math32.NewVector3(-8, 10, 8))
+	light.SetLinearDecay(0.2)
+	light.SetQuadraticDecay(0)
+	scene.Add(light)
+
+	// Write the mesh of the terrain, as it is currently moved, to an obj when O is pressed
+	a.Subscribe(window.OnKeyDown, func(name string, ev interface{}) {
+		if ev.(*window.KeyEvent).Key == window.KeyO {
+			if err := writeOutputs(mesh.terrain.HeightField(), []string{"terrain.obj"}, OutputOptions{}); err != nil {
+				printError(err)
+			}
+		}
+	})
+
+	// Create and add an axis helper to the scene
+	scene.Add(helper.NewAxes(0.5))
+
+	// Set background color to gray
+	a.Gls().ClearColor(0.3, 0.3, 0.3, 1.0)
+
+	// Run the application
+	a.Run(func(renderer *renderer.Renderer, deltaTime time.Duration) {
+		a.Gls().Clear(gls.DEPTH_BUFFER_BIT | gls.STENCIL_BUFFER_BIT | gls.COLOR_BUFFER_BIT)
+		renderer.Render(scene, cam)
+	})
+}
+
+/*
+ * Opens a g3n window with the mesh of a terrain, which can be moved with the sliders at the left of the window
+ * @param surface The generated terrain to view
+ */
+func viewTerrain(surface terrain.Terrain) error {
+	_, yBounds := surface.Grid().Bounds()
+	a, scene, cam := prepareScene(uint32(yBounds.Size() / 2))
+
+	mesh := newTerrainMesh(surface)
+	mat := material.NewStandard(math32.NewColor("darkgrey"))
+	scene.Add(graphic.NewMesh(mesh.geom, mat))
+
+	completeScene(a, scene, mesh, cam)
+	return nil
+}
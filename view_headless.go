@@ -0,0 +1,17 @@
+//go:build headless
+
+package main
+
+import (
+	"fmt"
+
+	"terrain-generation/terrain"
+)
+
+/*
+ * Headless builds leave out g3n and its window, so they can only write terrains to output files
+ * @param surface The generated terrain to view
+ */
+func viewTerrain(surface terrain.Terrain) error {
+	return fmt.Errorf("this is a headless build that can not open a window, pass output files to write the terrain to")
+}